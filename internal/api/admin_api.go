@@ -0,0 +1,195 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	ingextAPI "github.com/SecurityDo/ingext_api/api"
+	"github.com/SecurityDo/ingext_api/client"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+)
+
+// appSecret is the Secret holding the app token of the ingext app
+const appSecret = "app-secret"
+
+// ReplaceSecretValue sets key of a Secret to newValue, only if it still holds oldValue.
+// The update carries the resourceVersion read, so concurrent writes are detected and
+// the replacement is retried on the fresh object.
+func (k *K8sClusterClient) ReplaceSecretValue(ctx context.Context, namespace, secretName, key, oldValue, newValue string) error {
+	if k.clientset == nil {
+		return fmt.Errorf("k8s client not initialized")
+	}
+
+	secrets := k.clientset.CoreV1().Secrets(namespace)
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		secret, err := secrets.Get(ctx, secretName, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to get secret '%s' in namespace '%s': %w", secretName, namespace, err)
+		}
+		if string(secret.Data[key]) != oldValue {
+			return fmt.Errorf("key %s of secret '%s' was changed by someone else, not overwriting it", key, secretName)
+		}
+		if secret.Data == nil {
+			secret.Data = make(map[string][]byte)
+		}
+		secret.Data[key] = []byte(newValue)
+		// Returns a Conflict error when the resourceVersion changed since the Get
+		_, err = secrets.Update(ctx, secret, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// CheckSecretWritable checks that key of a Secret holds value and that the Secret can be
+// updated, with a server-side dry run, so a failure is found before anything is changed.
+func (k *K8sClusterClient) CheckSecretWritable(ctx context.Context, namespace, secretName, key, value string) error {
+	if k.clientset == nil {
+		return fmt.Errorf("k8s client not initialized")
+	}
+
+	secrets := k.clientset.CoreV1().Secrets(namespace)
+	secret, err := secrets.Get(ctx, secretName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get secret '%s' in namespace '%s': %w", secretName, namespace, err)
+	}
+	current, ok := secret.Data[key]
+	if !ok {
+		return fmt.Errorf("secret '%s' has no key %s (the token in use comes from another key), not rotating it", secretName, key)
+	}
+	if string(current) != value {
+		return fmt.Errorf("key %s of secret '%s' doesn't hold the token in use, not rotating it", key, secretName)
+	}
+	if _, err := secrets.Update(ctx, secret, metav1.UpdateOptions{DryRun: []string{metav1.DryRunAll}}); err != nil {
+		return fmt.Errorf("secret '%s' in namespace '%s' can't be updated: %w", secretName, namespace, err)
+	}
+	return nil
+}
+
+// usesSecret reports whether a pod template mounts the Secret or reads it in its environment
+func usesSecret(spec *corev1.PodSpec, secretName string) bool {
+	for _, v := range spec.Volumes {
+		if v.Secret != nil && v.Secret.SecretName == secretName {
+			return true
+		}
+		if v.Projected != nil {
+			for _, s := range v.Projected.Sources {
+				if s.Secret != nil && s.Secret.Name == secretName {
+					return true
+				}
+			}
+		}
+	}
+	for _, c := range append(spec.InitContainers, spec.Containers...) {
+		for _, e := range c.EnvFrom {
+			if e.SecretRef != nil && e.SecretRef.Name == secretName {
+				return true
+			}
+		}
+		for _, e := range c.Env {
+			if e.ValueFrom != nil && e.ValueFrom.SecretKeyRef != nil && e.ValueFrom.SecretKeyRef.Name == secretName {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RestartSecretConsumers triggers a rollout restart, as 'kubectl rollout restart' does, of the
+// Deployments and StatefulSets of the namespace using the Secret. It returns their names.
+func (k *K8sClusterClient) RestartSecretConsumers(ctx context.Context, namespace, secretName string) ([]string, error) {
+	if k.clientset == nil {
+		return nil, fmt.Errorf("k8s client not initialized")
+	}
+
+	patch := []byte(fmt.Sprintf(`{"spec":{"template":{"metadata":{"annotations":{"kubectl.kubernetes.io/restartedAt":%q}}}}}`, time.Now().Format(time.RFC3339)))
+	var restarted []string
+
+	deployments, err := k.clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments in namespace '%s': %w", namespace, err)
+	}
+	for _, d := range deployments.Items {
+		if !usesSecret(&d.Spec.Template.Spec, secretName) {
+			continue
+		}
+		if _, err := k.clientset.AppsV1().Deployments(namespace).Patch(ctx, d.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{}); err != nil {
+			return restarted, fmt.Errorf("failed to restart deployment '%s': %w", d.Name, err)
+		}
+		restarted = append(restarted, "Deployment/"+d.Name)
+	}
+
+	statefulSets, err := k.clientset.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return restarted, fmt.Errorf("failed to list statefulsets in namespace '%s': %w", namespace, err)
+	}
+	for _, s := range statefulSets.Items {
+		if !usesSecret(&s.Spec.Template.Spec, secretName) {
+			continue
+		}
+		if _, err := k.clientset.AppsV1().StatefulSets(namespace).Patch(ctx, s.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{}); err != nil {
+			return restarted, fmt.Errorf("failed to restart statefulset '%s': %w", s.Name, err)
+		}
+		restarted = append(restarted, "StatefulSet/"+s.Name)
+	}
+	return restarted, nil
+}
+
+// CheckAppTokenWritable checks that the token read by Login is the token key of the app
+// secret and that it can be replaced, before a new token is requested
+func (c *Client) CheckAppTokenWritable(ctx context.Context) error {
+	if err := c.k8sClient.CheckSecretWritable(ctx, c.Namespace, appSecret, "token", c.token); err != nil {
+		c.Logger.Error("app secret can't be rotated", "error", err, "namespace", c.Namespace)
+		return err
+	}
+	return nil
+}
+
+// RotateAppToken asks the platform for a new app token. The token isn't stored anywhere yet.
+func (c *Client) RotateAppToken() (token string, err error) {
+
+	authService := ingextAPI.NewAuthService(c.ingextClient)
+
+	token, err = authService.RotateAppToken()
+
+	if err != nil {
+		c.Logger.Error("failed to rotate app token", "error", err)
+		return "", fmt.Errorf("failed to rotate app token: %w", err)
+	}
+	return token, nil
+}
+
+// VerifyAppToken checks that the platform accepts the token with an authenticated call
+func (c *Client) VerifyAppToken(token string) error {
+
+	authService := ingextAPI.NewAuthService(client.NewIngextClient(c.siteURL, token, false, c.Logger))
+
+	if _, err := authService.ListRole(); err != nil {
+		c.Logger.Error("app token verification failed", "error", err)
+		return fmt.Errorf("app token verification failed: %w", err)
+	}
+	return nil
+}
+
+// StoreAppToken replaces the token of the app secret, read by Login, with the new one
+func (c *Client) StoreAppToken(ctx context.Context, token string) error {
+	if err := c.k8sClient.ReplaceSecretValue(ctx, c.Namespace, appSecret, "token", c.token, token); err != nil {
+		c.Logger.Error("failed to update app secret", "error", err, "namespace", c.Namespace)
+		return err
+	}
+	c.token = token
+	c.ingextClient = client.NewIngextClient(c.siteURL, token, false, c.Logger)
+	return nil
+}
+
+// RestartAppTokenConsumers restarts the workloads using the app secret, so they pick up a new token
+func (c *Client) RestartAppTokenConsumers(ctx context.Context) ([]string, error) {
+	restarted, err := c.k8sClient.RestartSecretConsumers(ctx, c.Namespace, appSecret)
+	if err != nil {
+		c.Logger.Error("failed to restart workloads", "error", err, "namespace", c.Namespace)
+		return restarted, err
+	}
+	return restarted, nil
+}
@@ -61,3 +61,15 @@ func (c *Client) ListUser() (users []*model.UserEntry, err error) {
 	}
 	return users, nil
 }
+
+func (c *Client) ListRole() (roles []*model.RoleEntry, err error) {
+
+	authService := ingextAPI.NewAuthService(c.ingextClient)
+
+	roles, err = authService.ListRole()
+	if err != nil {
+		c.Logger.Error("failed to list role", "error", err)
+		return nil, fmt.Errorf("failed to list role: %w", err)
+	}
+	return roles, nil
+}
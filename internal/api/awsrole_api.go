@@ -15,7 +15,7 @@ func (c *Client) AddAssumedRole(roleName, roleARN, roleExternalID string) (id st
 
 	if err != nil {
 		c.Logger.Error("failed to add assumed role", "error", err, "name", roleName, "role", roleARN)
-		return "", fmt.Errorf("failed to add user: %w", err)
+		return "", fmt.Errorf("failed to add assumed role: %w", err)
 	}
 	return id, nil
 }
@@ -28,7 +28,7 @@ func (c *Client) DeleteAssumedRole(roleID string) (err error) {
 
 	if err != nil {
 		c.Logger.Error("failed to delete assumed role", "error", err, "id", roleID)
-		return fmt.Errorf("failed to delete user: %w", err)
+		return fmt.Errorf("failed to delete assumed role: %w", err)
 	}
 	return nil
 }
@@ -45,3 +45,44 @@ func (c *Client) ListAssumedRole() (roles []*model.InstanceRole, err error) {
 	}
 	return roles, nil
 }
+
+func (c *Client) GetAssumedRole(roleID string) (role *model.InstanceRole, err error) {
+
+	platformService := ingextAPI.NewPlatformService(c.ingextClient)
+
+	role, err = platformService.GetAssumedRole(roleID)
+
+	if err != nil {
+		c.Logger.Error("failed to get assumed role", "error", err, "id", roleID)
+		return nil, fmt.Errorf("failed to get assumed role: %w", err)
+	}
+	return role, nil
+}
+
+func (c *Client) UpdateAssumedRole(role *model.InstanceRole) (err error) {
+
+	platformService := ingextAPI.NewPlatformService(c.ingextClient)
+
+	err = platformService.UpdateAssumedRole(role)
+
+	if err != nil {
+		c.Logger.Error("failed to update assumed role", "error", err, "id", role.ID)
+		return fmt.Errorf("failed to update assumed role: %w", err)
+	}
+	return nil
+}
+
+// GetPodIdentityRole returns the ARN of the IAM role the Ingext pods run as through
+// EKS Pod Identity, the principal that assumes the registered roles.
+func (c *Client) GetPodIdentityRole() (roleARN string, err error) {
+
+	platformService := ingextAPI.NewPlatformService(c.ingextClient)
+
+	roleARN, err = platformService.GetPodIdentityRole()
+
+	if err != nil {
+		c.Logger.Error("failed to get pod identity role", "error", err)
+		return "", fmt.Errorf("failed to get pod identity role: %w", err)
+	}
+	return roleARN, nil
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"fmt"
+
+	ingextAPI "github.com/SecurityDo/ingext_api/api"
+	"github.com/SecurityDo/ingext_api/model"
+)
+
+func (c *Client) AddFederatedIdentity(name, clientID, tenantID string) (id string, err error) {
+
+	platformService := ingextAPI.NewPlatformService(c.ingextClient)
+
+	id, err = platformService.AddFederatedIdentity(name, clientID, tenantID)
+
+	if err != nil {
+		c.Logger.Error("failed to add federated identity", "error", err, "name", name, "clientId", clientID)
+		return "", fmt.Errorf("failed to add federated identity: %w", err)
+	}
+	return id, nil
+}
+
+func (c *Client) DeleteFederatedIdentity(identityID string) (err error) {
+
+	platformService := ingextAPI.NewPlatformService(c.ingextClient)
+
+	err = platformService.DeleteFederatedIdentity(identityID)
+
+	if err != nil {
+		c.Logger.Error("failed to delete federated identity", "error", err, "id", identityID)
+		return fmt.Errorf("failed to delete federated identity: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) ListFederatedIdentity() (identities []*model.FederatedIdentity, err error) {
+
+	platformService := ingextAPI.NewPlatformService(c.ingextClient)
+
+	identities, err = platformService.ListFederatedIdentity()
+
+	if err != nil {
+		c.Logger.Error("failed to list federated identity", "error", err)
+		return nil, fmt.Errorf("failed to list federated identity: %w", err)
+	}
+	return identities, nil
+}
@@ -31,6 +31,10 @@ type Client struct {
 	// Embed the K8s helper
 	k8sClient    *K8sClusterClient
 	ingextClient *client.IngextClient // If you have a separate client for ingext
+
+	// siteURL and token are the platform endpoint and app token read by Login
+	siteURL string
+	token   string
 }
 
 // Option 1: Constructor injection (Recommended)
@@ -53,6 +57,15 @@ func NewClient(logger *slog.Logger) *Client {
 var IngextAppAPI *Client
 
 func (c *Client) Init(cluster, namespace string, kubeContext string) error {
+	if err := c.Connect(cluster, namespace, kubeContext); err != nil {
+		return err
+	}
+	return c.Login()
+}
+
+// Connect only connects to Kubernetes, for commands that inspect the cluster
+// without calling the platform (and must work when the platform is down)
+func (c *Client) Connect(cluster, namespace string, kubeContext string) error {
 	c.Cluster = cluster
 	c.Namespace = namespace
 
@@ -63,16 +76,22 @@ func (c *Client) Init(cluster, namespace string, kubeContext string) error {
 		return err
 	}
 	c.Logger.Info("connected to kubernetes cluster", "context", kubeContext)
+	return nil
+}
+
+// Login creates the platform client from the app secret and site config of the namespace
+func (c *Client) Login() error {
+	namespace := c.Namespace
 
 	// TODO: Perform actual login / connection logic here
-	c.Logger.Debug("Connecting to cluster ...\n", "cluster", cluster, "namespace", namespace)
+	c.Logger.Debug("Connecting to cluster ...\n", "cluster", c.Cluster, "namespace", namespace)
 
-	token, err := c.k8sClient.GetAppSecret(namespace, "app-secret", "token")
+	token, err := c.k8sClient.GetAppSecret(namespace, appSecret, "token")
 	if err != nil {
 		return fmt.Errorf("failed to get app secret token: %s", err)
 	}
 
-	configText, err := c.k8sClient.GetAppConfig(namespace, "ingext-community-config", "site_config.json")
+	configText, err := c.k8sClient.GetAppConfig(namespace, siteConfigMap, "site_config.json")
 	if err != nil {
 		return fmt.Errorf("failed to get app config: %s", err)
 	}
@@ -88,6 +107,8 @@ func (c *Client) Init(cluster, namespace string, kubeContext string) error {
 	ingextClient := client.NewIngextClient(config.SiteURL, token, false, c.Logger)
 
 	c.ingextClient = ingextClient
+	c.siteURL = config.SiteURL
+	c.token = token
 
 	c.Logger.Info("initialized ingext client",
 		"siteURL", config.SiteURL,
@@ -101,3 +122,12 @@ func (c *Client) Call(functionName string, functionArgs json.RawMessage) error {
 	// TODO: Implementation
 	return nil
 }
+
+// GetK8sSecret reads a key of a Kubernetes Secret in the connected cluster.
+// An empty namespace means the namespace of the ingext app.
+func (c *Client) GetK8sSecret(namespace, secretName, key string) (string, error) {
+	if namespace == "" {
+		namespace = c.Namespace
+	}
+	return c.k8sClient.GetSecretValue(namespace, secretName, key)
+}
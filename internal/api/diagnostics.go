@@ -0,0 +1,100 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// siteConfigMap is the ConfigMap holding the site config of the ingext app
+const siteConfigMap = "ingext-community-config"
+
+// PodInfo is the status of a pod, without its spec (which may hold env values)
+type PodInfo struct {
+	Name   string            `json:"name"`
+	Node   string            `json:"node,omitempty"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Status corev1.PodStatus  `json:"status"`
+}
+
+// EventInfo is an event of an object in the namespace
+type EventInfo struct {
+	Time    time.Time `json:"time"`
+	Type    string    `json:"type"`
+	Object  string    `json:"object"`
+	Reason  string    `json:"reason"`
+	Message string    `json:"message"`
+	Count   int32     `json:"count"`
+}
+
+// ListEvents returns all events of the namespace, oldest first
+func (k *K8sClusterClient) ListEvents(ctx context.Context, namespace string) ([]*EventInfo, error) {
+	if k.clientset == nil {
+		return nil, fmt.Errorf("k8s client not initialized")
+	}
+	events, err := k.clientset.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events in namespace '%s': %w", namespace, err)
+	}
+	infos := make([]*EventInfo, 0, len(events.Items))
+	for _, e := range events.Items {
+		infos = append(infos, &EventInfo{
+			Time:    eventTime(&e),
+			Type:    e.Type,
+			Object:  fmt.Sprintf("%s/%s", e.InvolvedObject.Kind, e.InvolvedObject.Name),
+			Reason:  e.Reason,
+			Message: e.Message,
+			Count:   max(e.Count, 1),
+		})
+	}
+	return infos, nil
+}
+
+// GetConfigMapData returns all keys of a ConfigMap
+func (k *K8sClusterClient) GetConfigMapData(ctx context.Context, namespace, name string) (map[string]string, error) {
+	if k.clientset == nil {
+		return nil, fmt.Errorf("k8s client not initialized")
+	}
+	config, err := k.clientset.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get configMap '%s' in namespace '%s': %w", name, namespace, err)
+	}
+	return config.Data, nil
+}
+
+// ListPodInfo returns the status of the pods of the namespace
+func (c *Client) ListPodInfo(ctx context.Context) ([]*PodInfo, error) {
+	pods, err := c.k8sClient.ListPods(ctx, c.Namespace, "")
+	if err != nil {
+		c.Logger.Error("failed to list pods", "error", err, "namespace", c.Namespace)
+		return nil, err
+	}
+	infos := make([]*PodInfo, 0, len(pods))
+	for _, pod := range pods {
+		infos = append(infos, &PodInfo{Name: pod.Name, Node: pod.Spec.NodeName, Labels: pod.Labels, Status: pod.Status})
+	}
+	return infos, nil
+}
+
+// ListEvents returns all events of the namespace of the ingext app
+func (c *Client) ListEvents(ctx context.Context) ([]*EventInfo, error) {
+	events, err := c.k8sClient.ListEvents(ctx, c.Namespace)
+	if err != nil {
+		c.Logger.Error("failed to list events", "error", err, "namespace", c.Namespace)
+		return nil, err
+	}
+	return events, nil
+}
+
+// GetSiteConfigMap returns the keys of the site config ConfigMap, unredacted
+func (c *Client) GetSiteConfigMap(ctx context.Context) (map[string]string, error) {
+	data, err := c.k8sClient.GetConfigMapData(ctx, c.Namespace, siteConfigMap)
+	if err != nil {
+		c.Logger.Error("failed to get site config", "error", err, "namespace", c.Namespace)
+		return nil, err
+	}
+	return data, nil
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"fmt"
+
+	ingextAPI "github.com/SecurityDo/ingext_api/api"
+	"github.com/SecurityDo/ingext_api/model"
+)
+
+func (c *Client) AddServiceAccount(name, email string) (id string, err error) {
+
+	platformService := ingextAPI.NewPlatformService(c.ingextClient)
+
+	id, err = platformService.AddServiceAccount(name, email)
+
+	if err != nil {
+		c.Logger.Error("failed to add service account", "error", err, "name", name, "email", email)
+		return "", fmt.Errorf("failed to add service account: %w", err)
+	}
+	return id, nil
+}
+
+func (c *Client) DeleteServiceAccount(accountID string) (err error) {
+
+	platformService := ingextAPI.NewPlatformService(c.ingextClient)
+
+	err = platformService.DeleteServiceAccount(accountID)
+
+	if err != nil {
+		c.Logger.Error("failed to delete service account", "error", err, "id", accountID)
+		return fmt.Errorf("failed to delete service account: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) ListServiceAccount() (accounts []*model.ServiceAccount, err error) {
+
+	platformService := ingextAPI.NewPlatformService(c.ingextClient)
+
+	accounts, err = platformService.ListServiceAccount()
+
+	if err != nil {
+		c.Logger.Error("failed to list service account", "error", err)
+		return nil, fmt.Errorf("failed to list service account: %w", err)
+	}
+	return accounts, nil
+}
@@ -45,3 +45,58 @@ func (c *Client) ListIntegration() (entries []*model.Integration, err error) {
 	}
 	return entries, nil
 }
+
+func (c *Client) GetIntegration(id string) (entry *model.Integration, err error) {
+
+	platformService := ingextAPI.NewPlatformService(c.ingextClient)
+
+	entry, err = platformService.GetIntegration(id)
+
+	if err != nil {
+		c.Logger.Error("failed to get integration", "error", err, "id", id)
+		return nil, fmt.Errorf("failed to get integration: %s", err.Error())
+	}
+	return entry, nil
+}
+
+func (c *Client) UpdateIntegration(entry *model.Integration) (err error) {
+
+	platformService := ingextAPI.NewPlatformService(c.ingextClient)
+
+	err = platformService.UpdateIntegration(entry)
+
+	if err != nil {
+		c.Logger.Error("failed to update integration", "error", err, "id", entry.ID)
+		return fmt.Errorf("failed to update integration: %s", err.Error())
+	}
+	return nil
+}
+
+// ListIntegrationType returns the integration types with their config and secret parameter schemas
+func (c *Client) ListIntegrationType() (types []*model.IntegrationType, err error) {
+
+	platformService := ingextAPI.NewPlatformService(c.ingextClient)
+
+	types, err = platformService.ListIntegrationTypes()
+
+	if err != nil {
+		c.Logger.Error("failed to list integration type", "error", err)
+		return nil, fmt.Errorf("failed to list integration type: %s", err.Error())
+	}
+	return types, nil
+}
+
+// TestIntegration asks the platform to check the connectivity and credentials of an integration.
+// err reports a failed call, a failed check is reported in result.
+func (c *Client) TestIntegration(id string) (result *model.IntegrationTestResult, err error) {
+
+	platformService := ingextAPI.NewPlatformService(c.ingextClient)
+
+	result, err = platformService.TestIntegration(id)
+
+	if err != nil {
+		c.Logger.Error("failed to test integration", "error", err, "id", id)
+		return nil, fmt.Errorf("failed to test integration: %s", err.Error())
+	}
+	return result, nil
+}
@@ -108,3 +108,20 @@ func (k *K8sClusterClient) GetAppConfig(namespace, configName string, key string
 
 	return "", fmt.Errorf("configmap '%s' found, but contains no key %s", configName, key)
 }
+
+// GetSecretValue returns one key of a Secret, without the fallbacks of GetAppSecret
+func (k *K8sClusterClient) GetSecretValue(namespace, secretName, key string) (string, error) {
+	if k.clientset == nil {
+		return "", fmt.Errorf("k8s client not initialized")
+	}
+
+	secret, err := k.clientset.CoreV1().Secrets(namespace).Get(context.TODO(), secretName, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get secret '%s' in namespace '%s': %w", secretName, namespace, err)
+	}
+
+	if b, ok := secret.Data[key]; ok {
+		return string(b), nil
+	}
+	return "", fmt.Errorf("secret '%s' found, but contains no key %s", secretName, key)
+}
@@ -0,0 +1,224 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// logPollInterval is how often pods are re-listed while following, to pick up
+// new pods and containers that restarted
+const logPollInterval = 2 * time.Second
+
+// componentLabels are the pod labels matched against a component name
+var componentLabels = []string{"app.kubernetes.io/component", "app.kubernetes.io/name", "app", "component"}
+
+// LogOptions selects the pods and the part of their logs to stream
+type LogOptions struct {
+	// Component matches pods by name prefix or by one of componentLabels, empty for all pods
+	Component string
+	Follow    bool
+	// Since limits the logs to the last duration, 0 for all
+	Since time.Duration
+	// TailLines limits the logs to the last lines of each container, 0 for all
+	TailLines int64
+}
+
+// LogLine is one line of a container's log
+type LogLine struct {
+	Pod       string
+	Container string
+	Time      time.Time
+	Text      string
+}
+
+// matchesComponent reports whether the pod belongs to the component
+func matchesComponent(pod *corev1.Pod, component string) bool {
+	if component == "" || strings.HasPrefix(pod.Name, component) {
+		return true
+	}
+	for _, label := range componentLabels {
+		if pod.Labels[label] == component {
+			return true
+		}
+	}
+	return false
+}
+
+// ListPods returns the pods of the namespace matching the component
+func (k *K8sClusterClient) ListPods(ctx context.Context, namespace, component string) ([]corev1.Pod, error) {
+	if k.clientset == nil {
+		return nil, fmt.Errorf("k8s client not initialized")
+	}
+	pods, err := k.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods in namespace '%s': %w", namespace, err)
+	}
+	var matched []corev1.Pod
+	for _, pod := range pods.Items {
+		if matchesComponent(&pod, component) {
+			matched = append(matched, pod)
+		}
+	}
+	return matched, nil
+}
+
+// streamContainerLog sends the log lines of one container written after since (all when zero),
+// limited to the last tailLines lines when not zero. With previous, the log of the container's
+// last terminated run is read instead. Lines carry their timestamp so a stream re-opened after
+// a restart can skip what was already sent.
+func (k *K8sClusterClient) streamContainerLog(ctx context.Context, namespace, pod, container string, follow, previous bool, tailLines int64, since time.Time, lines chan<- LogLine) (last time.Time, err error) {
+	opts := &corev1.PodLogOptions{Container: container, Follow: follow && !previous, Previous: previous, Timestamps: true}
+	if tailLines > 0 {
+		opts.TailLines = &tailLines
+	}
+	if !since.IsZero() {
+		t := metav1.NewTime(since)
+		opts.SinceTime = &t
+	}
+	stream, err := k.clientset.CoreV1().Pods(namespace).GetLogs(pod, opts).Stream(ctx)
+	if err != nil {
+		return since, fmt.Errorf("failed to stream logs of %s/%s: %w", pod, container, err)
+	}
+	defer stream.Close()
+
+	last = since
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		ts, text, _ := strings.Cut(scanner.Text(), " ")
+		t, err := time.Parse(time.RFC3339Nano, ts)
+		if err != nil {
+			// Not a timestamped line (e.g. a continuation of a long line)
+			t, text = last, scanner.Text()
+		} else if !t.After(since) && !since.IsZero() {
+			// SinceTime has a one second precision, skip lines already sent
+			continue
+		}
+		last = t
+		select {
+		case lines <- LogLine{Pod: pod, Container: container, Time: t, Text: text}:
+		case <-ctx.Done():
+			return last, nil
+		}
+	}
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		return last, fmt.Errorf("failed to read logs of %s/%s: %w", pod, container, err)
+	}
+	return last, nil
+}
+
+// StreamLogs sends the log lines of every container of the matching pods to lines,
+// streaming them concurrently, and closes lines when done. While following, new pods
+// and restarted containers are picked up until ctx is canceled.
+func (c *Client) StreamLogs(ctx context.Context, opts LogOptions, lines chan<- LogLine) error {
+	defer close(lines)
+
+	var since time.Time
+	if opts.Since > 0 {
+		since = time.Now().Add(-opts.Since)
+	}
+
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		active = make(map[string]bool)      // pod/container being streamed
+		last   = make(map[string]time.Time) // time of the last line sent per pod/container
+	)
+	// start streams a container's log, or the log of its last run with previous.
+	// It returns false when the container is already being streamed.
+	start := func(pod, container string, previous bool) bool {
+		key := pod + "/" + container
+		mu.Lock()
+		defer mu.Unlock()
+		if active[key] {
+			return false
+		}
+		// Re-opened streams continue after the last line sent, without the tail limit
+		from, ok := last[key]
+		tail := int64(0)
+		if !ok {
+			from, tail = since, opts.TailLines
+		}
+		active[key] = true
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			t, err := c.k8sClient.streamContainerLog(ctx, c.Namespace, pod, container, opts.Follow, previous, tail, from, lines)
+			if err != nil {
+				c.Logger.Warn("log stream ended", "error", err)
+			}
+			mu.Lock()
+			active[key] = false
+			last[key] = t
+			mu.Unlock()
+		}()
+		return true
+	}
+
+	// started tracks the containers streamed at least once, so the logs of a
+	// terminated container, or of the last run of a waiting one, are only read once
+	started := make(map[string]bool)
+	poll := func() error {
+		pods, err := c.k8sClient.ListPods(ctx, c.Namespace, opts.Component)
+		if err != nil {
+			return err
+		}
+		for _, pod := range pods {
+			for _, cs := range pod.Status.ContainerStatuses {
+				key := pod.Name + "/" + cs.Name
+				if cs.State.Running != nil || (cs.State.Terminated != nil && !started[key]) {
+					started[key] = true
+					start(pod.Name, cs.Name, false)
+					continue
+				}
+				// A waiting container in a restart backoff (e.g. CrashLoopBackOff) has no
+				// log of its own, its last run's log tells why it keeps failing. Lines
+				// already sent while it was running are skipped.
+				if cs.State.Waiting != nil && cs.LastTerminationState.Terminated != nil {
+					run := fmt.Sprintf("%s#%d", key, cs.RestartCount)
+					if !started[run] && start(pod.Name, cs.Name, true) {
+						started[run] = true
+					}
+				}
+			}
+		}
+		return nil
+	}
+
+	if err := poll(); err != nil {
+		c.Logger.Error("failed to list pods", "error", err, "namespace", c.Namespace)
+		return err
+	}
+	if len(started) == 0 && !opts.Follow {
+		wg.Wait()
+		if opts.Component == "" {
+			return fmt.Errorf("no pods found in namespace '%s'", c.Namespace)
+		}
+		return fmt.Errorf("no pods found in namespace '%s' matching '%s'", c.Namespace, opts.Component)
+	}
+
+	if opts.Follow {
+		ticker := time.NewTicker(logPollInterval)
+		defer ticker.Stop()
+	loop:
+		for {
+			select {
+			case <-ctx.Done():
+				break loop
+			case <-ticker.C:
+				if err := poll(); err != nil && ctx.Err() == nil {
+					c.Logger.Warn("failed to refresh pods", "error", err)
+				}
+			}
+		}
+	}
+	wg.Wait()
+	return nil
+}
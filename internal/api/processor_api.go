@@ -0,0 +1,65 @@
+package api
+
+import (
+	"fmt"
+
+	ingextAPI "github.com/SecurityDo/ingext_api/api"
+	model "github.com/SecurityDo/ingext_api/model"
+)
+
+// AddProcessor deploys the processor content, creating a new version if the processor exists
+func (c *Client) AddProcessor(name string, content []byte) (version int, err error) {
+
+	platformService := ingextAPI.NewPlatformService(c.ingextClient)
+
+	version, err = platformService.AddProcessor(name, string(content))
+
+	if err != nil {
+		c.Logger.Error("failed to add processor", "error", err, "name", name)
+		return 0, fmt.Errorf("failed to add processor: %s", err.Error())
+	}
+	return version, nil
+}
+
+func (c *Client) GetProcessor(name string) (entry *model.Processor, err error) {
+
+	platformService := ingextAPI.NewPlatformService(c.ingextClient)
+
+	entry, err = platformService.GetProcessor(name)
+
+	if err != nil {
+		c.Logger.Error("failed to get processor", "error", err, "name", name)
+		return nil, fmt.Errorf("failed to get processor: %s", err.Error())
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("processor '%s' not found", name)
+	}
+	return entry, nil
+}
+
+func (c *Client) ListProcessorHistory(name string) (versions []*model.ProcessorVersion, err error) {
+
+	platformService := ingextAPI.NewPlatformService(c.ingextClient)
+
+	versions, err = platformService.ListProcessorHistory(name)
+
+	if err != nil {
+		c.Logger.Error("failed to list processor history", "error", err, "name", name)
+		return nil, fmt.Errorf("failed to list processor history: %s", err.Error())
+	}
+	return versions, nil
+}
+
+// RollbackProcessor makes a previous version current again
+func (c *Client) RollbackProcessor(name string, version int) (err error) {
+
+	platformService := ingextAPI.NewPlatformService(c.ingextClient)
+
+	err = platformService.RollbackProcessor(name, version)
+
+	if err != nil {
+		c.Logger.Error("failed to rollback processor", "error", err, "name", name, "version", version)
+		return fmt.Errorf("failed to rollback processor: %s", err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,265 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/SecurityDo/ingext_api/model"
+)
+
+// SendOptions controls how events are pushed to a source ingestion endpoint
+type SendOptions struct {
+	BatchSize int     // events per request
+	Rate      float64 // max events per second, 0 means unlimited
+	Retries   int     // retries per batch on network errors, 429 and 5xx
+	Gzip      bool    // gzip the request body
+}
+
+// SendResult counts events accepted and rejected by the source
+type SendResult struct {
+	Accepted int
+	Rejected int
+	Errors   []string
+}
+
+// sendRetryBackoff is the wait before the first retry of a batch, doubled on each retry
+var sendRetryBackoff = 500 * time.Millisecond
+
+// SendEvents reads newline-delimited events from r and posts them in batches to the
+// ingestion URL of a HEC or webhook source, using the protocol of the source type.
+func (c *Client) SendEvents(ctx context.Context, sourceID string, r io.Reader, opts SendOptions) (*SendResult, error) {
+	resp, err := c.GetDataSource(sourceID)
+	if err != nil {
+		return nil, err
+	}
+	if resp.URL == "" {
+		return nil, fmt.Errorf("source %s has no ingestion URL", sourceID)
+	}
+	target, err := newSendTarget(resp.Source, resp.URL, resp.Secret)
+	if err != nil {
+		return nil, err
+	}
+	return c.sendEvents(ctx, target, r, opts)
+}
+
+// sendTarget describes where and how batches of a source are posted
+type sendTarget struct {
+	url         string
+	authHeader  string
+	contentType string
+	encode      func(batch [][]byte) ([]byte, error)
+	// requireJSON rejects invalid lines one by one, before they can fail a whole batch
+	requireJSON bool
+}
+
+// newSendTarget picks the protocol of the source type
+func newSendTarget(source *model.DataSourceConfig, url string, secret json.RawMessage) (sendTarget, error) {
+	target := sendTarget{url: url, contentType: "application/json"}
+	token := secretToken(secret)
+	switch source.Type {
+	case "hec":
+		target.encode = encodeHECBatch
+		target.authHeader = "Splunk " + token
+	case "webhook":
+		if source.Format == "json" {
+			target.encode = encodeJSONArrayBatch
+			target.requireJSON = true
+		} else {
+			target.encode = encodeLineBatch
+			target.contentType = "text/plain"
+		}
+		target.authHeader = "Bearer " + token
+	default:
+		return target, fmt.Errorf("source type '%s' does not accept pushed events, only hec and webhook sources do", source.Type)
+	}
+	return target, nil
+}
+
+// sendEvents posts the events of r to target in batches
+func (c *Client) sendEvents(ctx context.Context, target sendTarget, r io.Reader, opts SendOptions) (*SendResult, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 100
+	}
+	// A batch never holds more than a second of events, so a low rate isn't sent as bursts
+	if opts.Rate > 0 && float64(opts.BatchSize) > opts.Rate {
+		opts.BatchSize = max(1, int(opts.Rate))
+	}
+
+	result := &SendResult{}
+	httpClient := &http.Client{Timeout: 30 * time.Second}
+	start := time.Now()
+	sent := 0
+
+	flush := func(batch [][]byte) error {
+		body, err := target.encode(batch)
+		if err != nil {
+			result.Rejected += len(batch)
+			result.Errors = append(result.Errors, err.Error())
+			return nil
+		}
+
+		// Rate limiting: a batch waits for the schedule implied by the rate, so the
+		// first one is sent right away and nothing waits after the last one
+		if opts.Rate > 0 {
+			due := start.Add(time.Duration(float64(sent) / opts.Rate * float64(time.Second)))
+			select {
+			case <-time.After(time.Until(due)):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		sent += len(batch)
+
+		if err := c.postBatch(ctx, httpClient, target, body, opts); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			result.Rejected += len(batch)
+			result.Errors = append(result.Errors, err.Error())
+		} else {
+			result.Accepted += len(batch)
+		}
+		return nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	var batch [][]byte
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if target.requireJSON && !json.Valid(line) {
+			result.Rejected++
+			result.Errors = append(result.Errors, fmt.Sprintf("line %d is not valid JSON", lineNo))
+			continue
+		}
+		batch = append(batch, append([]byte(nil), line...))
+		if len(batch) >= opts.BatchSize {
+			if err := flush(batch); err != nil {
+				return result, err
+			}
+			batch = nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return result, fmt.Errorf("failed to read events: %w", err)
+	}
+	if len(batch) > 0 {
+		if err := flush(batch); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// postBatch sends one request, retrying with exponential backoff on transient failures
+func (c *Client) postBatch(ctx context.Context, httpClient *http.Client, target sendTarget, body []byte, opts SendOptions) error {
+	if opts.Gzip {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(body); err != nil {
+			return fmt.Errorf("failed to compress batch: %w", err)
+		}
+		if err := zw.Close(); err != nil {
+			return fmt.Errorf("failed to compress batch: %w", err)
+		}
+		body = buf.Bytes()
+	}
+
+	backoff := sendRetryBackoff
+	var lastErr error
+	for attempt := 0; attempt <= opts.Retries; attempt++ {
+		if attempt > 0 {
+			c.Logger.Debug("retrying batch", "attempt", attempt, "error", lastErr)
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			backoff *= 2
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, target.url, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Authorization", target.authHeader)
+		req.Header.Set("Content-Type", target.contentType)
+		if opts.Gzip {
+			req.Header.Set("Content-Encoding", "gzip")
+		}
+
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		resp.Body.Close()
+
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return nil
+		}
+		lastErr = fmt.Errorf("source returned %s: %s", resp.Status, bytes.TrimSpace(respBody))
+		if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500 {
+			// Client errors won't succeed on retry
+			return lastErr
+		}
+	}
+	return lastErr
+}
+
+// secretToken extracts the token from a source secret, which is either
+// a plain JSON string or an object with a "token" field.
+func secretToken(raw json.RawMessage) string {
+	var token string
+	if err := json.Unmarshal(raw, &token); err == nil {
+		return token
+	}
+	var fields struct {
+		Token string `json:"token"`
+	}
+	_ = json.Unmarshal(raw, &fields)
+	return fields.Token
+}
+
+// encodeHECBatch wraps each event in a HEC envelope; lines that aren't JSON are sent as string events
+func encodeHECBatch(batch [][]byte) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, line := range batch {
+		event := json.RawMessage(line)
+		if !json.Valid(line) {
+			event, _ = json.Marshal(string(line))
+		}
+		b, err := json.Marshal(map[string]json.RawMessage{"event": event})
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode HEC event: %w", err)
+		}
+		buf.Write(b)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeJSONArrayBatch posts the batch as a JSON array, the lines were checked to be valid JSON
+func encodeJSONArrayBatch(batch [][]byte) ([]byte, error) {
+	events := make([]json.RawMessage, 0, len(batch))
+	for _, line := range batch {
+		events = append(events, line)
+	}
+	return json.Marshal(events)
+}
+
+// encodeLineBatch posts the batch as-is, one event per line
+func encodeLineBatch(batch [][]byte) ([]byte, error) {
+	return append(bytes.Join(batch, []byte("\n")), '\n'), nil
+}
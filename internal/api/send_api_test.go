@@ -0,0 +1,243 @@
+package api
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SecurityDo/ingext_api/model"
+)
+
+func TestSecretToken(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{raw: `"abc"`, want: "abc"},
+		{raw: `{"token":"abc","other":1}`, want: "abc"},
+		{raw: `{"other":"abc"}`, want: ""},
+		{raw: `42`, want: ""},
+		{raw: ``, want: ""},
+	}
+	for _, tt := range tests {
+		if got := secretToken(json.RawMessage(tt.raw)); got != tt.want {
+			t.Errorf("secretToken(%s) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestEncodeBatches(t *testing.T) {
+	batch := [][]byte{[]byte(`{"a":1}`), []byte(`plain text`)}
+	tests := []struct {
+		name   string
+		encode func([][]byte) ([]byte, error)
+		batch  [][]byte
+		want   string
+	}{
+		{name: "hec", encode: encodeHECBatch, batch: batch, want: "{\"event\":{\"a\":1}}\n{\"event\":\"plain text\"}\n"},
+		{name: "json array", encode: encodeJSONArrayBatch, batch: [][]byte{[]byte(`{"a":1}`), []byte(`2`)}, want: `[{"a":1},2]`},
+		{name: "lines", encode: encodeLineBatch, batch: batch, want: "{\"a\":1}\nplain text\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.encode(tt.batch)
+			if err != nil {
+				t.Fatalf("encode() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("encode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewSendTarget(t *testing.T) {
+	secret := json.RawMessage(`{"token":"t0k"}`)
+	tests := []struct {
+		source      model.DataSourceConfig
+		auth        string
+		contentType string
+		requireJSON bool
+		wantErr     bool
+	}{
+		{source: model.DataSourceConfig{Type: "hec", Format: "json"}, auth: "Splunk t0k", contentType: "application/json"},
+		{source: model.DataSourceConfig{Type: "webhook", Format: "json"}, auth: "Bearer t0k", contentType: "application/json", requireJSON: true},
+		{source: model.DataSourceConfig{Type: "webhook", Format: "text"}, auth: "Bearer t0k", contentType: "text/plain"},
+		{source: model.DataSourceConfig{Type: "s3", Format: "json"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		target, err := newSendTarget(&tt.source, "https://x", secret)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("newSendTarget(%s) = %+v, want an error", tt.source.Type, target)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("newSendTarget(%s) error = %v", tt.source.Type, err)
+		}
+		if target.authHeader != tt.auth || target.contentType != tt.contentType || target.requireJSON != tt.requireJSON {
+			t.Errorf("newSendTarget(%s/%s) = %q %q %v, want %q %q %v", tt.source.Type, tt.source.Format,
+				target.authHeader, target.contentType, target.requireJSON, tt.auth, tt.contentType, tt.requireJSON)
+		}
+	}
+}
+
+// testReceiver is an ingestion endpoint answering with the statuses given, then 200
+type testReceiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests int
+	bodies   []string
+	times    []time.Time
+}
+
+func (r *testReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body := io.Reader(req.Body)
+	if req.Header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(req.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		body = zr
+	}
+	b, _ := io.ReadAll(body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests++
+	r.times = append(r.times, time.Now())
+	if len(r.statuses) > 0 {
+		status := r.statuses[0]
+		r.statuses = r.statuses[1:]
+		if status != http.StatusOK {
+			http.Error(w, "nope", status)
+			return
+		}
+	}
+	if req.Header.Get("Authorization") != "Bearer t0k" {
+		http.Error(w, "bad token", http.StatusUnauthorized)
+		return
+	}
+	r.bodies = append(r.bodies, string(b))
+}
+
+func testClient() *Client {
+	return NewClient(slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func lineTarget(url string) sendTarget {
+	return sendTarget{url: url, authHeader: "Bearer t0k", contentType: "text/plain", encode: encodeLineBatch}
+}
+
+func TestSendEvents(t *testing.T) {
+	receiver := &testReceiver{}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	target := sendTarget{url: server.URL, authHeader: "Bearer t0k", contentType: "application/json", encode: encodeJSONArrayBatch, requireJSON: true}
+	input := "{\"a\":1}\n\nnot json\n{\"a\":2}\n{\"a\":3}\n"
+	result, err := testClient().sendEvents(context.Background(), target, strings.NewReader(input), SendOptions{BatchSize: 2, Gzip: true})
+	if err != nil {
+		t.Fatalf("sendEvents() error = %v", err)
+	}
+
+	want := &SendResult{Accepted: 3, Rejected: 1, Errors: []string{"line 3 is not valid JSON"}}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("sendEvents() = %+v, want %+v", result, want)
+	}
+	if wantBodies := []string{`[{"a":1},{"a":2}]`, `[{"a":3}]`}; !reflect.DeepEqual(receiver.bodies, wantBodies) {
+		t.Errorf("bodies = %q, want %q", receiver.bodies, wantBodies)
+	}
+}
+
+func TestSendEventsRetries(t *testing.T) {
+	sendRetryBackoff = time.Millisecond
+	t.Cleanup(func() { sendRetryBackoff = 500 * time.Millisecond })
+
+	tests := []struct {
+		name         string
+		statuses     []int
+		retries      int
+		wantAccepted int
+		wantRequests int
+		wantErr      string
+	}{
+		{name: "success", wantAccepted: 1, wantRequests: 1},
+		{name: "5xx is retried", statuses: []int{503, 500}, retries: 3, wantAccepted: 1, wantRequests: 3},
+		{name: "429 is retried", statuses: []int{429}, retries: 3, wantAccepted: 1, wantRequests: 2},
+		{name: "4xx is not retried", statuses: []int{400}, retries: 3, wantRequests: 1, wantErr: "source returned 400 Bad Request: nope"},
+		{name: "bad token is not retried", statuses: []int{401}, retries: 3, wantRequests: 1, wantErr: "401"},
+		{name: "retries run out", statuses: []int{503, 503, 503}, retries: 2, wantRequests: 3, wantErr: "503"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver := &testReceiver{statuses: tt.statuses}
+			server := httptest.NewServer(receiver)
+			defer server.Close()
+
+			result, err := testClient().sendEvents(context.Background(), lineTarget(server.URL), strings.NewReader("event\n"), SendOptions{Retries: tt.retries})
+			if err != nil {
+				t.Fatalf("sendEvents() error = %v", err)
+			}
+			if result.Accepted != tt.wantAccepted || receiver.requests != tt.wantRequests {
+				t.Errorf("accepted %d in %d requests, want %d in %d", result.Accepted, receiver.requests, tt.wantAccepted, tt.wantRequests)
+			}
+			if tt.wantErr != "" && (len(result.Errors) != 1 || !strings.Contains(result.Errors[0], tt.wantErr)) {
+				t.Errorf("errors = %q, want %q", result.Errors, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSendEventsRate(t *testing.T) {
+	receiver := &testReceiver{}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	// 4 events per second: batches of 4, the second one a second after the first
+	input := strings.Repeat("event\n", 8)
+	start := time.Now()
+	result, err := testClient().sendEvents(context.Background(), lineTarget(server.URL), strings.NewReader(input), SendOptions{BatchSize: 100, Rate: 4})
+	if err != nil {
+		t.Fatalf("sendEvents() error = %v", err)
+	}
+	elapsed := time.Since(start)
+
+	if result.Accepted != 8 || len(receiver.bodies) != 2 {
+		t.Fatalf("accepted %d in %d batches, want 8 in 2", result.Accepted, len(receiver.bodies))
+	}
+	if gap := receiver.times[1].Sub(receiver.times[0]); gap < 900*time.Millisecond {
+		t.Errorf("second batch sent %v after the first, want about 1s", gap)
+	}
+	// Nothing waits after the last batch
+	if elapsed > 1800*time.Millisecond {
+		t.Errorf("sendEvents() took %v, want about 1s", elapsed)
+	}
+}
+
+func TestSendEventsCanceled(t *testing.T) {
+	receiver := &testReceiver{}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := testClient().sendEvents(ctx, lineTarget(server.URL), strings.NewReader(strings.Repeat("event\n", 4)), SendOptions{Rate: 1})
+	if err != context.DeadlineExceeded {
+		t.Errorf("sendEvents() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if len(receiver.bodies) != 1 {
+		t.Errorf("%d batches sent before the cancel, want 1", len(receiver.bodies))
+	}
+}
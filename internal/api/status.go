@@ -0,0 +1,284 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// WorkloadStatus is the state of a Deployment or StatefulSet and its pods
+type WorkloadStatus struct {
+	Kind     string
+	Name     string
+	Desired  int32
+	Ready    int32
+	Restarts int32
+	Images   []string
+	// Waiting lists the reasons pods are stuck, e.g. CrashLoopBackOff or ImagePullBackOff
+	Waiting []string
+}
+
+// Healthy reports whether all desired replicas are ready and no container is stuck
+func (w *WorkloadStatus) Healthy() bool {
+	return w.Ready >= w.Desired && len(w.Waiting) == 0
+}
+
+// VolumeStatus is the state of a PersistentVolumeClaim. UsedBytes and CapacityBytes
+// come from the kubelet and are 0 when the volume isn't mounted or stats are unavailable.
+type VolumeStatus struct {
+	Name          string
+	Phase         string
+	Size          string
+	UsedBytes     int64
+	CapacityBytes int64
+}
+
+// Usage returns the used fraction of the volume, or -1 when unknown
+func (v *VolumeStatus) Usage() float64 {
+	if v.CapacityBytes <= 0 {
+		return -1
+	}
+	return float64(v.UsedBytes) / float64(v.CapacityBytes)
+}
+
+// WarningEvent is a Warning event of an object in the namespace
+type WarningEvent struct {
+	Time    time.Time
+	Object  string
+	Reason  string
+	Message string
+	Count   int32
+}
+
+// ListWorkloads returns the Deployments and StatefulSets of the namespace with the
+// restarts, images and waiting reasons of their pods
+func (k *K8sClusterClient) ListWorkloads(ctx context.Context, namespace string) ([]*WorkloadStatus, error) {
+	if k.clientset == nil {
+		return nil, fmt.Errorf("k8s client not initialized")
+	}
+
+	var workloads []*WorkloadStatus
+	deployments, err := k.clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments in namespace '%s': %w", namespace, err)
+	}
+	for _, d := range deployments.Items {
+		w := &WorkloadStatus{Kind: "Deployment", Name: d.Name, Desired: 1, Ready: d.Status.ReadyReplicas}
+		if d.Spec.Replicas != nil {
+			w.Desired = *d.Spec.Replicas
+		}
+		if err := k.addPodStatus(ctx, namespace, d.Spec.Selector, w); err != nil {
+			return nil, err
+		}
+		workloads = append(workloads, w)
+	}
+
+	statefulSets, err := k.clientset.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets in namespace '%s': %w", namespace, err)
+	}
+	for _, s := range statefulSets.Items {
+		w := &WorkloadStatus{Kind: "StatefulSet", Name: s.Name, Desired: 1, Ready: s.Status.ReadyReplicas}
+		if s.Spec.Replicas != nil {
+			w.Desired = *s.Spec.Replicas
+		}
+		if err := k.addPodStatus(ctx, namespace, s.Spec.Selector, w); err != nil {
+			return nil, err
+		}
+		workloads = append(workloads, w)
+	}
+	return workloads, nil
+}
+
+func (k *K8sClusterClient) addPodStatus(ctx context.Context, namespace string, selector *metav1.LabelSelector, w *WorkloadStatus) error {
+	sel, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil || sel.Empty() {
+		return nil
+	}
+	pods, err := k.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: sel.String()})
+	if err != nil {
+		return fmt.Errorf("failed to list pods of %s '%s': %w", w.Kind, w.Name, err)
+	}
+
+	images := make(map[string]bool)
+	waiting := make(map[string]bool)
+	for _, pod := range pods.Items {
+		for _, c := range pod.Spec.Containers {
+			images[c.Image] = true
+		}
+		for _, cs := range pod.Status.ContainerStatuses {
+			w.Restarts += cs.RestartCount
+			if cs.State.Waiting != nil && cs.State.Waiting.Reason != "" && cs.State.Waiting.Reason != "ContainerCreating" {
+				waiting[fmt.Sprintf("%s: %s", pod.Name, cs.State.Waiting.Reason)] = true
+			}
+		}
+	}
+	w.Images = sortedSet(images)
+	w.Waiting = sortedSet(waiting)
+	return nil
+}
+
+// ListVolumes returns the PersistentVolumeClaims of the namespace. Usage is read from
+// the kubelet stats of the nodes running pods of the namespace, when they are reachable.
+func (k *K8sClusterClient) ListVolumes(ctx context.Context, namespace string) ([]*VolumeStatus, error) {
+	if k.clientset == nil {
+		return nil, fmt.Errorf("k8s client not initialized")
+	}
+
+	claims, err := k.clientset.CoreV1().PersistentVolumeClaims(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list persistent volume claims in namespace '%s': %w", namespace, err)
+	}
+	if len(claims.Items) == 0 {
+		return nil, nil
+	}
+
+	usage := k.volumeUsage(ctx, namespace)
+	var volumes []*VolumeStatus
+	for _, c := range claims.Items {
+		v := &VolumeStatus{Name: c.Name, Phase: string(c.Status.Phase)}
+		if size, ok := c.Status.Capacity[corev1.ResourceStorage]; ok {
+			v.Size = size.String()
+		}
+		if u, ok := usage[c.Name]; ok {
+			v.UsedBytes, v.CapacityBytes = u.UsedBytes, u.CapacityBytes
+		}
+		volumes = append(volumes, v)
+	}
+	return volumes, nil
+}
+
+// kubeletSummary is the part of the kubelet /stats/summary response with volume usage
+type kubeletSummary struct {
+	Pods []struct {
+		Volume []struct {
+			UsedBytes     *int64 `json:"usedBytes"`
+			CapacityBytes *int64 `json:"capacityBytes"`
+			PVCRef        *struct {
+				Name      string `json:"name"`
+				Namespace string `json:"namespace"`
+			} `json:"pvcRef"`
+		} `json:"volume"`
+	} `json:"pods"`
+}
+
+// volumeUsage maps claim names to their usage. Errors (e.g. no RBAC for nodes/proxy)
+// only mean usage is unknown, so they are ignored.
+func (k *K8sClusterClient) volumeUsage(ctx context.Context, namespace string) map[string]*VolumeStatus {
+	usage := make(map[string]*VolumeStatus)
+
+	pods, err := k.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return usage
+	}
+	nodes := make(map[string]bool)
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName != "" {
+			nodes[pod.Spec.NodeName] = true
+		}
+	}
+
+	for _, node := range sortedSet(nodes) {
+		raw, err := k.clientset.CoreV1().RESTClient().Get().
+			AbsPath("/api/v1/nodes", node, "proxy/stats/summary").DoRaw(ctx)
+		if err != nil {
+			continue
+		}
+		var summary kubeletSummary
+		if err := json.Unmarshal(raw, &summary); err != nil {
+			continue
+		}
+		for _, pod := range summary.Pods {
+			for _, v := range pod.Volume {
+				if v.PVCRef == nil || v.PVCRef.Namespace != namespace || v.UsedBytes == nil || v.CapacityBytes == nil {
+					continue
+				}
+				usage[v.PVCRef.Name] = &VolumeStatus{UsedBytes: *v.UsedBytes, CapacityBytes: *v.CapacityBytes}
+			}
+		}
+	}
+	return usage
+}
+
+// ListWarningEvents returns the Warning events of the namespace seen since the given time, newest first
+func (k *K8sClusterClient) ListWarningEvents(ctx context.Context, namespace string, since time.Time) ([]*WarningEvent, error) {
+	if k.clientset == nil {
+		return nil, fmt.Errorf("k8s client not initialized")
+	}
+
+	events, err := k.clientset.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{FieldSelector: "type=" + corev1.EventTypeWarning})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events in namespace '%s': %w", namespace, err)
+	}
+
+	var warnings []*WarningEvent
+	for _, e := range events.Items {
+		t := eventTime(&e)
+		if t.Before(since) {
+			continue
+		}
+		warnings = append(warnings, &WarningEvent{
+			Time:    t,
+			Object:  fmt.Sprintf("%s/%s", e.InvolvedObject.Kind, e.InvolvedObject.Name),
+			Reason:  e.Reason,
+			Message: e.Message,
+			Count:   max(e.Count, 1),
+		})
+	}
+	sort.Slice(warnings, func(i, j int) bool { return warnings[i].Time.After(warnings[j].Time) })
+	return warnings, nil
+}
+
+// eventTime is the last time the event was seen, whichever API version wrote it
+func eventTime(e *corev1.Event) time.Time {
+	switch {
+	case !e.LastTimestamp.IsZero():
+		return e.LastTimestamp.Time
+	case e.Series != nil && !e.Series.LastObservedTime.IsZero():
+		return e.Series.LastObservedTime.Time
+	case !e.EventTime.IsZero():
+		return e.EventTime.Time
+	default:
+		return e.FirstTimestamp.Time
+	}
+}
+
+func sortedSet(set map[string]bool) []string {
+	list := make([]string, 0, len(set))
+	for s := range set {
+		list = append(list, s)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// ClusterStatus is the health of the ingext app in its namespace
+type ClusterStatus struct {
+	Workloads []*WorkloadStatus
+	Volumes   []*VolumeStatus
+	Events    []*WarningEvent
+}
+
+// GetClusterStatus collects the workloads, volumes and Warning events seen since the given time
+func (c *Client) GetClusterStatus(ctx context.Context, since time.Time) (*ClusterStatus, error) {
+	status := &ClusterStatus{}
+	var err error
+	if status.Workloads, err = c.k8sClient.ListWorkloads(ctx, c.Namespace); err != nil {
+		c.Logger.Error("failed to list workloads", "error", err, "namespace", c.Namespace)
+		return nil, err
+	}
+	if status.Volumes, err = c.k8sClient.ListVolumes(ctx, c.Namespace); err != nil {
+		c.Logger.Error("failed to list volumes", "error", err, "namespace", c.Namespace)
+		return nil, err
+	}
+	if status.Events, err = c.k8sClient.ListWarningEvents(ctx, c.Namespace, since); err != nil {
+		c.Logger.Error("failed to list events", "error", err, "namespace", c.Namespace)
+		return nil, err
+	}
+	return status, nil
+}
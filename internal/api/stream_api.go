@@ -1,7 +1,10 @@
 package api
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"time"
 
 	ingextAPI "github.com/SecurityDo/ingext_api/api"
 	model "github.com/SecurityDo/ingext_api/model"
@@ -45,3 +48,152 @@ func (c *Client) AddRouter(routerConfig *model.RouterConfig) (id string, err err
 	}
 	return resp.ID, nil
 }
+
+func (c *Client) ListDataSource() (entries []*model.DataSourceConfig, err error) {
+
+	platformService := ingextAPI.NewPlatformService(c.ingextClient)
+
+	entries, err = platformService.ListDataSource()
+
+	if err != nil {
+		c.Logger.Error("failed to list data source", "error", err)
+		return nil, fmt.Errorf("failed to list data source: %s", err.Error())
+	}
+	return entries, nil
+}
+
+func (c *Client) GetDataSource(id string) (resp *ingextAPI.GetDataSourceResponse, err error) {
+
+	platformService := ingextAPI.NewPlatformService(c.ingextClient)
+
+	resp, err = platformService.GetDataSource(id)
+
+	if err != nil {
+		c.Logger.Error("failed to get data source", "error", err, "id", id)
+		return nil, fmt.Errorf("failed to get data source: %s", err.Error())
+	}
+	if resp == nil || resp.Source == nil {
+		return nil, fmt.Errorf("data source %s not found", id)
+	}
+	return resp, nil
+}
+
+func (c *Client) UpdateDataSource(source *model.DataSourceConfig) (err error) {
+
+	platformService := ingextAPI.NewPlatformService(c.ingextClient)
+
+	err = platformService.UpdateDataSource(source)
+
+	if err != nil {
+		c.Logger.Error("failed to update data source", "error", err, "id", source.ID)
+		return fmt.Errorf("failed to update data source: %s", err.Error())
+	}
+	return nil
+}
+
+func (c *Client) DeleteDataSource(id string) (err error) {
+
+	platformService := ingextAPI.NewPlatformService(c.ingextClient)
+
+	err = platformService.DeleteDataSource(id)
+
+	if err != nil {
+		c.Logger.Error("failed to delete data source", "error", err, "id", id)
+		return fmt.Errorf("failed to delete data source: %s", err.Error())
+	}
+	return nil
+}
+
+// RotateDataSourceSecret invalidates the current secret of a source and returns the new one.
+func (c *Client) RotateDataSourceSecret(id string) (secret json.RawMessage, err error) {
+
+	platformService := ingextAPI.NewPlatformService(c.ingextClient)
+
+	secret, err = platformService.RotateDataSourceSecret(id)
+
+	if err != nil {
+		c.Logger.Error("failed to rotate data source secret", "error", err, "id", id)
+		return nil, fmt.Errorf("failed to rotate data source secret: %s", err.Error())
+	}
+	return secret, nil
+}
+
+// TailEvents polls the events sampled by the platform for a source, sink or router and
+// calls handle for each one, until ctx is cancelled or handle returns false.
+func (c *Client) TailEvents(ctx context.Context, kind, id string, interval time.Duration, handle func(event json.RawMessage) bool) error {
+
+	platformService := ingextAPI.NewPlatformService(c.ingextClient)
+
+	type sample struct {
+		resp *ingextAPI.SampleEventsResponse
+		err  error
+	}
+
+	cursor := ""
+	for {
+		// SampleEvents takes no context: wait for it in a goroutine so Ctrl-C
+		// doesn't have to wait for a blocking poll to return
+		done := make(chan sample, 1)
+		go func(cursor string) {
+			resp, err := platformService.SampleEvents(&ingextAPI.SampleEventsRequest{
+				Kind:   kind,
+				ID:     id,
+				Cursor: cursor,
+			})
+			done <- sample{resp, err}
+		}(cursor)
+
+		var resp *ingextAPI.SampleEventsResponse
+		select {
+		case <-ctx.Done():
+			return nil
+		case s := <-done:
+			if s.err != nil {
+				c.Logger.Error("failed to sample events", "error", s.err, "kind", kind, "id", id)
+				return fmt.Errorf("failed to sample events: %s", s.err.Error())
+			}
+			resp = s.resp
+		}
+		// No response is no new events, poll again from the same cursor
+		if resp != nil {
+			for _, event := range resp.Events {
+				if ctx.Err() != nil || !handle(event) {
+					return nil
+				}
+			}
+			cursor = resp.Cursor
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+func (c *Client) ListDataSink() (entries []*model.DataSinkConfig, err error) {
+
+	platformService := ingextAPI.NewPlatformService(c.ingextClient)
+
+	entries, err = platformService.ListDataSink()
+
+	if err != nil {
+		c.Logger.Error("failed to list data sink", "error", err)
+		return nil, fmt.Errorf("failed to list data sink: %s", err.Error())
+	}
+	return entries, nil
+}
+
+func (c *Client) ListRouter() (entries []*model.RouterConfig, err error) {
+
+	platformService := ingextAPI.NewPlatformService(c.ingextClient)
+
+	entries, err = platformService.ListRouter()
+
+	if err != nil {
+		c.Logger.Error("failed to list router", "error", err)
+		return nil, fmt.Errorf("failed to list router: %s", err.Error())
+	}
+	return entries, nil
+}
@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var rotateRestart bool
+
+var adminCmd = &cobra.Command{
+	Use:   "admin",
+	Short: "Administer the ingext app",
+}
+
+// Example usage:
+// ingext admin rotate-token --restart
+var rotateTokenCmd = &cobra.Command{
+	Use:   "rotate-token",
+	Short: "Rotate the platform app token stored in the app-secret Secret",
+	Long:  "Checks that app-secret can be updated, requests a new app token from the platform, checks that it works, and stores it in the token key of the app-secret Secret. With --restart, the deployments and statefulsets using app-secret are restarted to pick it up.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(cmd.Context(), time.Minute)
+		defer cancel()
+
+		// Once rotated, the old token may stop working: make sure the new one can be stored first
+		cmd.PrintErrln("Checking that app-secret can be updated...")
+		if err := AppAPI.CheckAppTokenWritable(ctx); err != nil {
+			return err
+		}
+
+		cmd.PrintErrln("Requesting a new app token...")
+		token, err := AppAPI.RotateAppToken()
+		if err != nil {
+			return err
+		}
+
+		// Never store a token the platform doesn't accept
+		cmd.PrintErrln("Verifying the new token...")
+		if err := AppAPI.VerifyAppToken(token); err != nil {
+			return fmt.Errorf("%w, app-secret was left unchanged", err)
+		}
+
+		cmd.PrintErrln("Updating app-secret...")
+		if err := AppAPI.StoreAppToken(ctx, token); err != nil {
+			return fmt.Errorf("%w; the platform issued a new token that wasn't stored, run rotate-token again", err)
+		}
+
+		if rotateRestart {
+			cmd.PrintErrln("Restarting the workloads using app-secret...")
+			restarted, err := AppAPI.RestartAppTokenConsumers(ctx)
+			for _, name := range restarted {
+				cmd.PrintErrln("Restarted", name)
+			}
+			if err != nil {
+				return err
+			}
+			if len(restarted) == 0 {
+				cmd.PrintErrln("No workloads use app-secret.")
+			}
+		} else {
+			cmd.PrintErrln("Workloads reading app-secret at startup keep the old token until restarted (use --restart).")
+		}
+
+		cmd.PrintErrln("App token rotated successfully.")
+		return nil
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(adminCmd)
+	adminCmd.AddCommand(rotateTokenCmd)
+
+	rotateTokenCmd.Flags().BoolVar(&rotateRestart, "restart", false, "Rollout restart the deployments and statefulsets using app-secret")
+}
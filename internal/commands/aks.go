@@ -0,0 +1,104 @@
+package commands
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	identityName     string
+	identityClientID string
+	identityTenantID string
+	identityID       string
+)
+
+// guidPattern matches Azure client and tenant IDs
+var guidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+func validateGUID(flag, value string) error {
+	if !guidPattern.MatchString(value) {
+		return fmt.Errorf("invalid --%s '%s', expected a GUID (xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx)", flag, value)
+	}
+	return nil
+}
+
+var aksCmd = &cobra.Command{
+	Use:         "aks",
+	Short:       "Manage AKS workload identity managed identities",
+	Annotations: map[string]string{providerCommand: "aks"},
+}
+
+var addFederatedIdentityCmd = &cobra.Command{
+	Use:   "add-federated-identity",
+	Short: "Add a managed identity for Workload Identity",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateGUID("clientId", identityClientID); err != nil {
+			return err
+		}
+		if err := validateGUID("tenantId", identityTenantID); err != nil {
+			return err
+		}
+
+		cmd.PrintErrln("Adding Azure managed identity...")
+		id, err := AppAPI.AddFederatedIdentity(identityName, identityClientID, identityTenantID)
+		if err != nil {
+			return err
+		}
+
+		cmd.PrintErrln("Identity added successfully: ", id)
+		fmt.Fprintln(cmd.OutOrStdout(), id)
+		return nil
+	},
+}
+
+var delFederatedIdentityCmd = &cobra.Command{
+	Use:   "del-federated-identity",
+	Short: "Delete a managed identity for Workload Identity",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.PrintErrln("Deleting Azure managed identity...")
+		if err := AppAPI.DeleteFederatedIdentity(identityID); err != nil {
+			return err
+		}
+
+		cmd.PrintErrln("Identity deleted successfully: ", identityID)
+		return nil
+	},
+}
+
+var listFederatedIdentityCmd = &cobra.Command{
+	Use:   "list-federated-identity",
+	Short: "List managed identities for Workload Identity",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		identities, err := AppAPI.ListFederatedIdentity()
+		if err != nil {
+			return err
+		}
+		cmd.PrintErrln("Listing Azure managed identities...")
+		if len(identities) == 0 {
+			cmd.Println("No identities found.")
+			return nil
+		}
+
+		for _, identity := range identities {
+			fmt.Fprintf(cmd.OutOrStdout(), "Identity ID: %s, Name: %s, Client ID: %s, Tenant ID: %s\n", identity.ID, identity.DisplayName, identity.ClientID, identity.TenantID)
+		}
+		return nil
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(aksCmd)
+	aksCmd.AddCommand(addFederatedIdentityCmd, delFederatedIdentityCmd, listFederatedIdentityCmd)
+
+	addFederatedIdentityCmd.Flags().StringVar(&identityName, "name", "", "Display name of the identity")
+	addFederatedIdentityCmd.Flags().StringVar(&identityClientID, "clientId", "", "Client ID of the managed identity")
+	addFederatedIdentityCmd.Flags().StringVar(&identityTenantID, "tenantId", "", "Microsoft Entra tenant ID of the managed identity")
+	_ = addFederatedIdentityCmd.MarkFlagRequired("name")
+	_ = addFederatedIdentityCmd.MarkFlagRequired("clientId")
+	_ = addFederatedIdentityCmd.MarkFlagRequired("tenantId")
+
+	delFederatedIdentityCmd.Flags().StringVar(&identityID, "id", "", "Identity ID")
+	_ = delFederatedIdentityCmd.MarkFlagRequired("id")
+}
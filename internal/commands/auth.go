@@ -1,8 +1,7 @@
 package commands
 
 import (
-	//"fmt"
-
+	"fmt"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -28,6 +27,9 @@ var userAddCmd = &cobra.Command{
 	Short: "Add a user",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		//cmd.PrintErrf("Adding user: %s (Role: %s)\n", authName, authRole)
+		if err := validateRole(cmd, authRole); err != nil {
+			return err
+		}
 		err := AppAPI.AddUser(authName, authDisplayName, authRole, authOrg)
 		if err != nil {
 			cmd.PrintErrf("Error adding user: %s %v\n", authName, err)
@@ -68,6 +70,61 @@ var userListCmd = &cobra.Command{
 	},
 }
 
+var roleListCmd = &cobra.Command{
+	Use:   "list-role",
+	Short: "List roles available on the platform",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		roles, err := AppAPI.ListRole()
+		if err != nil {
+			cmd.PrintErrf("Error listing role: %v\n", err)
+			return err
+		}
+		for _, role := range roles {
+			fmt.Fprintf(cmd.OutOrStdout(), "Role: %s, Description: %s\n", role.Name, role.Description)
+		}
+		return nil
+	},
+}
+
+// validateRole checks the role against the roles known to the platform, so a
+// typo is reported before the server answers with an opaque error.
+// If the role list cannot be fetched, the check is skipped and the server decides.
+func validateRole(cmd *cobra.Command, role string) error {
+	roles, err := AppAPI.ListRole()
+	if err != nil {
+		cmd.PrintErrf("Warning: unable to validate role '%s': %v\n", role, err)
+		return nil
+	}
+
+	names := make([]string, 0, len(roles))
+	for _, r := range roles {
+		if r.Name == role {
+			return nil
+		}
+		names = append(names, r.Name)
+	}
+	return fmt.Errorf("invalid role '%s', must be one of: %s", role, strings.Join(names, ", "))
+}
+
+// completeRoleNames provides shell completion for --role.
+func completeRoleNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if err := initCompletionAPI(); err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	roles, err := AppAPI.ListRole()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var names []string
+	for _, r := range roles {
+		if strings.HasPrefix(r.Name, toComplete) {
+			names = append(names, r.Name+"\t"+r.Description)
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
 /*
 // Nouns (Token)
 var authAddTokenCmd = &cobra.Command{
@@ -82,7 +139,7 @@ var authAddTokenCmd = &cobra.Command{
 
 func init() {
 	RootCmd.AddCommand(authCmd)
-	authCmd.AddCommand(userAddCmd, userDelCmd, userListCmd)
+	authCmd.AddCommand(userAddCmd, userDelCmd, userListCmd, roleListCmd)
 
 	// Add 'user' and 'token' to 'add'
 	//authAddCmd.AddCommand(authAddUserCmd, authDelUserCmd)
@@ -90,12 +147,13 @@ func init() {
 	// Add flags to the leaf commands (or persistent flags to the verbs)
 	userAddCmd.Flags().StringVar(&authName, "name", "", "Name of the user")
 	userAddCmd.Flags().StringVar(&authDisplayName, "displayName", "", "Display name")
-	userAddCmd.Flags().StringVar(&authRole, "role", "", "Role (admin|analyst)")
+	userAddCmd.Flags().StringVar(&authRole, "role", "", "Role (see 'ingext auth list-role')")
 	userAddCmd.Flags().StringVar(&authOrg, "org", "ingext", "Organization")
 
 	// Mark required
 	_ = userAddCmd.MarkFlagRequired("name")
 	_ = userAddCmd.MarkFlagRequired("role")
+	_ = userAddCmd.RegisterFlagCompletionFunc("role", completeRoleNames)
 	//_ = authAddUserCmd.MarkFlagRequired("org")
 
 	userDelCmd.Flags().StringVar(&authName, "name", "", "Name of the user")
@@ -1,34 +1,151 @@
 package commands
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"text/tabwriter"
+
 	"github.com/spf13/cobra"
 )
 
 var (
-	roleName        string
-	roleDisplayName string
-	roleExternalID  string
-	roleARN         string
-	roleID          string
+	roleName          string
+	roleDisplayName   string
+	roleExternalID    string
+	roleARN           string
+	roleID            string
+	roleGenExternalID bool
+	rolePrintPolicy   bool
+	rolePrincipal     string
 )
 
+// roleARNPattern matches IAM role ARNs: arn:<partition>:iam::<account>:role/<path/><name>
+var roleARNPattern = regexp.MustCompile(`^arn:(aws|aws-cn|aws-us-gov):iam::(\d{12}):role/((?:[\w+=,.@-]+/)*)([\w+=,.@-]{1,64})$`)
+
+// externalIDPattern is the character set AWS accepts for sts:ExternalId (2 to 1224 characters)
+var externalIDPattern = regexp.MustCompile(`^[\w+=,.@:/-]+$`)
+
+// iamRoleARN is a parsed IAM role ARN
+type iamRoleARN struct {
+	Partition string
+	AccountID string
+	Path      string
+	Name      string
+}
+
+func parseRoleARN(arn string) (*iamRoleARN, error) {
+	m := roleARNPattern.FindStringSubmatch(arn)
+	if m == nil {
+		return nil, fmt.Errorf("invalid role ARN '%s', expected arn:aws:iam::<12 digit account>:role/<name>", arn)
+	}
+	return &iamRoleARN{Partition: m[1], AccountID: m[2], Path: "/" + m[3], Name: m[4]}, nil
+}
+
+// resolveExternalID returns the --externalId value, or a random one with --generate-external-id
+func resolveExternalID(cmd *cobra.Command) (string, error) {
+	if roleGenExternalID {
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err != nil {
+			return "", fmt.Errorf("failed to generate external ID: %w", err)
+		}
+		id := hex.EncodeToString(b)
+		cmd.PrintErrln("Generated external ID:", id)
+		return id, nil
+	}
+	if roleExternalID != "" && (len(roleExternalID) < 2 || len(roleExternalID) > 1224 || !externalIDPattern.MatchString(roleExternalID)) {
+		return "", fmt.Errorf("invalid external ID, it must be 2 to 1224 characters of letters, digits and +=,.@:/-")
+	}
+	return roleExternalID, nil
+}
+
+// trustPolicy is an IAM role trust policy document
+type trustPolicy struct {
+	Version   string                 `json:"Version"`
+	Statement []trustPolicyStatement `json:"Statement"`
+}
+
+type trustPolicyStatement struct {
+	Effect    string                       `json:"Effect"`
+	Principal map[string]string            `json:"Principal"`
+	Action    []string                     `json:"Action"`
+	Condition map[string]map[string]string `json:"Condition,omitempty"`
+}
+
+// renderTrustPolicy returns the trust policy that lets principal, the role the Ingext
+// pods get from EKS Pod Identity, assume the role. sts:TagSession is needed because
+// Pod Identity sessions carry transitive session tags.
+func renderTrustPolicy(principal, externalID string) ([]byte, error) {
+	if _, err := parseRoleARN(principal); err != nil {
+		return nil, fmt.Errorf("invalid pod identity principal: %w", err)
+	}
+	stmt := trustPolicyStatement{
+		Effect:    "Allow",
+		Principal: map[string]string{"AWS": principal},
+		Action:    []string{"sts:AssumeRole", "sts:TagSession"},
+	}
+	if externalID != "" {
+		stmt.Condition = map[string]map[string]string{
+			"StringEquals": {"sts:ExternalId": externalID},
+		}
+	}
+	return json.MarshalIndent(trustPolicy{Version: "2012-10-17", Statement: []trustPolicyStatement{stmt}}, "", "  ")
+}
+
+// printTrustPolicy writes the trust policy of a role to stdout. The principal is
+// --principal when given, otherwise the platform's pod identity role.
+func printTrustPolicy(cmd *cobra.Command, externalID string) error {
+	principal := rolePrincipal
+	if principal == "" {
+		var err error
+		if principal, err = AppAPI.GetPodIdentityRole(); err != nil {
+			return fmt.Errorf("%w (use --principal to set it)", err)
+		}
+	}
+	policy, err := renderTrustPolicy(principal, externalID)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), string(policy))
+	return nil
+}
+
 var eksCmd = &cobra.Command{
-	Use:   "eks",
-	Short: "Manage EKS pod identity assumed roles",
+	Use:         "eks",
+	Short:       "Manage EKS pod identity assumed roles",
+	Annotations: map[string]string{providerCommand: "eks"},
 }
 
 var addAssumedRoleCmd = &cobra.Command{
 	Use:   "add-assumed-role",
 	Short: "Add Assumed Roles for Pod Identity Agent",
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if _, err := parseRoleARN(roleARN); err != nil {
+			return err
+		}
+		externalID, err := resolveExternalID(cmd)
+		if err != nil {
+			return err
+		}
+
 		cmd.PrintErrln("Adding AWS Role...")
 		// Just call the global interface
-		id, err := AppAPI.AddAssumedRole(roleName, roleARN, roleExternalID)
+		id, err := AppAPI.AddAssumedRole(roleName, roleARN, externalID)
 		if err != nil {
 			return err
 		}
 
 		cmd.PrintErrln("Role added successfully: ", id)
+		if rolePrintPolicy {
+			// The policy is the machine output here, the ID is already on stderr
+			cmd.PrintErrln("Trust policy to attach to the role:")
+			if err := printTrustPolicy(cmd, externalID); err != nil {
+				return fmt.Errorf("role %s was created but %w", id, err)
+			}
+			return nil
+		}
 		cmd.Println(id)
 		return nil
 	},
@@ -74,19 +191,99 @@ var listAssumedRoleCmd = &cobra.Command{
 	},
 }
 
+var getAssumedRoleCmd = &cobra.Command{
+	Use:   "get-assumed-role",
+	Short: "Show an Assumed Role for Pod Identity Agent",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		role, err := AppAPI.GetAssumedRole(roleID)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "ID\t%s\n", role.ID)
+		fmt.Fprintf(w, "Name\t%s\n", role.DisplayName)
+		fmt.Fprintf(w, "Role ARN\t%s\n", role.RoleARN)
+		fmt.Fprintf(w, "External ID\t%s\n", role.ExternalID)
+		return w.Flush()
+	},
+}
+
+var updateAssumedRoleCmd = &cobra.Command{
+	Use:   "update-assumed-role",
+	Short: "Update the name or external ID of an Assumed Role without re-creating it",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		role, err := AppAPI.GetAssumedRole(roleID)
+		if err != nil {
+			return err
+		}
+
+		if cmd.Flags().Changed("name") {
+			role.DisplayName = roleName
+		}
+		if cmd.Flags().Changed("externalId") || roleGenExternalID {
+			if role.ExternalID, err = resolveExternalID(cmd); err != nil {
+				return err
+			}
+			cmd.PrintErrln("Remember to update the sts:ExternalId condition of the role's trust policy.")
+		}
+
+		cmd.PrintErrln("Updating AWS Role...")
+		if err := AppAPI.UpdateAssumedRole(role); err != nil {
+			return err
+		}
+		cmd.PrintErrln("Role updated successfully: ", roleID)
+		return nil
+	},
+}
+
+var trustPolicyCmd = &cobra.Command{
+	Use:   "trust-policy",
+	Short: "Print the IAM trust policy an Assumed Role needs",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		role, err := AppAPI.GetAssumedRole(roleID)
+		if err != nil {
+			return err
+		}
+		return printTrustPolicy(cmd, role.ExternalID)
+	},
+}
+
 func init() {
 	RootCmd.AddCommand(eksCmd)
 	eksCmd.AddCommand(addAssumedRoleCmd, delAssumedRoleCmd, listAssumedRoleCmd) // Add del/update similarly
+	eksCmd.AddCommand(getAssumedRoleCmd, updateAssumedRoleCmd, trustPolicyCmd)
 
-	addAssumedRoleCmd.Flags().StringVar(&roleName, "name", "", "Name of the user")
+	addAssumedRoleCmd.Flags().StringVar(&roleName, "name", "", "Display name of the role")
 	//addAssumedRoleCmd.Flags().StringVar(&roleDisplayName, "displayName", "", "Display name")
-	addAssumedRoleCmd.Flags().StringVar(&roleARN, "roleArn", "", "Role ARN to assume")
+	addAssumedRoleCmd.Flags().StringVar(&roleARN, "roleArn", "", "Role ARN to assume (arn:aws:iam::<account>:role/<name>)")
 	addAssumedRoleCmd.Flags().StringVar(&roleExternalID, "externalId", "", "External ID (optional)")
+	addAssumedRoleCmd.Flags().BoolVar(&roleGenExternalID, "generate-external-id", false, "Generate a random external ID")
+	addAssumedRoleCmd.Flags().BoolVar(&rolePrintPolicy, "print-trust-policy", false, "Print the role's trust policy to stdout instead of its ID")
+	addAssumedRoleCmd.Flags().StringVar(&rolePrincipal, "principal", "", "Role ARN of the Ingext pods (default: read from the platform)")
+	addAssumedRoleCmd.MarkFlagsMutuallyExclusive("externalId", "generate-external-id")
 
 	// Mark required
 	_ = addAssumedRoleCmd.MarkFlagRequired("name")
 	_ = addAssumedRoleCmd.MarkFlagRequired("roleArn")
 
+	delAssumedRoleCmd.Flags().StringVar(&roleID, "id", "", "Role ID")
+	_ = delAssumedRoleCmd.MarkFlagRequired("id")
+
+	getAssumedRoleCmd.Flags().StringVar(&roleID, "id", "", "Role ID")
+	_ = getAssumedRoleCmd.MarkFlagRequired("id")
+
+	updateAssumedRoleCmd.Flags().StringVar(&roleID, "id", "", "Role ID")
+	updateAssumedRoleCmd.Flags().StringVar(&roleName, "name", "", "Display name of the role")
+	updateAssumedRoleCmd.Flags().StringVar(&roleExternalID, "externalId", "", "External ID")
+	updateAssumedRoleCmd.Flags().BoolVar(&roleGenExternalID, "generate-external-id", false, "Generate a random external ID")
+	updateAssumedRoleCmd.MarkFlagsMutuallyExclusive("externalId", "generate-external-id")
+	_ = updateAssumedRoleCmd.MarkFlagRequired("id")
+
+	trustPolicyCmd.Flags().StringVar(&roleID, "id", "", "Role ID")
+	trustPolicyCmd.Flags().StringVar(&rolePrincipal, "principal", "", "Role ARN of the Ingext pods (default: read from the platform)")
+	_ = trustPolicyCmd.MarkFlagRequired("id")
+
 	//streamAddCmd.AddCommand(streamAddSourceCmd)
 	// Add other leaf commands: sink, router, connection
 }
@@ -0,0 +1,47 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseRoleARN(t *testing.T) {
+	tests := []struct {
+		arn     string
+		want    *iamRoleARN
+		wantErr bool
+	}{
+		{arn: "arn:aws:iam::123456789012:role/ingext", want: &iamRoleARN{Partition: "aws", AccountID: "123456789012", Path: "/", Name: "ingext"}},
+		{arn: "arn:aws:iam::123456789012:role/service/ingext/reader", want: &iamRoleARN{Partition: "aws", AccountID: "123456789012", Path: "/service/ingext/", Name: "reader"}},
+		{arn: "arn:aws-cn:iam::123456789012:role/a+b=c,d.e@f-g_h", want: &iamRoleARN{Partition: "aws-cn", AccountID: "123456789012", Path: "/", Name: "a+b=c,d.e@f-g_h"}},
+		{arn: "arn:aws-us-gov:iam::123456789012:role/ingext", want: &iamRoleARN{Partition: "aws-us-gov", AccountID: "123456789012", Path: "/", Name: "ingext"}},
+		{arn: "arn:aws:iam::123456789012:role/" + strings.Repeat("r", 64), want: &iamRoleARN{Partition: "aws", AccountID: "123456789012", Path: "/", Name: strings.Repeat("r", 64)}},
+		{arn: "arn:aws:iam::123456789012:role/" + strings.Repeat("r", 65), wantErr: true},
+		{arn: "arn:aws:iam::12345678901:role/ingext", wantErr: true},
+		{arn: "arn:aws:iam::123456789012:user/ingext", wantErr: true},
+		{arn: "arn:aws:iam::123456789012:role/", wantErr: true},
+		{arn: "arn:aws:iam::123456789012:role/path/", wantErr: true},
+		{arn: "arn:aws:sts::123456789012:role/ingext", wantErr: true},
+		{arn: "arn:azure:iam::123456789012:role/ingext", wantErr: true},
+		{arn: "ingext", wantErr: true},
+		{arn: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.arn, func(t *testing.T) {
+			got, err := parseRoleARN(tt.arn)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseRoleARN() = %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseRoleARN() error = %v", err)
+			}
+			if *got != *tt.want {
+				t.Errorf("parseRoleARN() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
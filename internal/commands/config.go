@@ -44,8 +44,15 @@ var configCmd = &cobra.Command{
 		// This creates a nested structure in the YAML file.
 		prefix := fmt.Sprintf("clusters.%s.", targetCluster)
 
-		// We always save the provider (defaults to 'eks' via flag if not typed)
-		viper.Set(prefix+"provider", confProvider)
+		// The provider is saved when given, or for a new profile (defaults to 'eks' via flag
+		// if not typed). Editing another field keeps the provider of an existing profile.
+		if cmd.Flags().Changed("provider") || viper.GetString(prefix+"provider") == "" {
+			if err := validateProvider(confProvider); err != nil {
+				cmd.PrintErrln("Error:", err)
+				return
+			}
+			viper.Set(prefix+"provider", confProvider)
+		}
 
 		if namespace != "" {
 			viper.Set(prefix+"namespace", namespace)
@@ -188,6 +195,9 @@ func init() {
 	// Default value "eks" is set here for the FLAG
 	configCmd.Flags().StringVar(&confProvider, "provider", "eks", "Provider (eks|aks|gke)")
 	configCmd.Flags().StringVar(&confContext, "context", "", "Kubeconfig context name")
+	_ = configCmd.RegisterFlagCompletionFunc("provider", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return providerNames(), cobra.ShellCompDirectiveNoFileComp
+	})
 
 	// Set the global default for Viper as well (in case user views config without setting it)
 	viper.SetDefault("provider", "eks")
@@ -0,0 +1,112 @@
+package commands
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	gsaName       string
+	gsaEmail      string
+	gsaID         string
+	gsaProject    string
+	gsaK8sAccount string
+)
+
+// gsaEmailPattern matches Google service account emails and captures the project,
+// e.g. ingext-reader@my-project.iam.gserviceaccount.com
+var gsaEmailPattern = regexp.MustCompile(`^[a-z][a-z0-9-]{4,28}[a-z0-9]@([a-z][a-z0-9-]{4,28}[a-z0-9])\.iam\.gserviceaccount\.com$`)
+
+// workloadIdentityBinding returns the gcloud command that lets the Kubernetes service
+// account of the Ingext pods impersonate the Google service account
+func workloadIdentityBinding(email, project, namespace, k8sAccount string) string {
+	return fmt.Sprintf("gcloud iam service-accounts add-iam-policy-binding %s \\\n"+
+		"  --role roles/iam.workloadIdentityUser \\\n"+
+		"  --member \"serviceAccount:%s.svc.id.goog[%s/%s]\"", email, project, namespace, k8sAccount)
+}
+
+var gkeCmd = &cobra.Command{
+	Use:         "gke",
+	Short:       "Manage GKE Workload Identity service accounts",
+	Annotations: map[string]string{providerCommand: "gke"},
+}
+
+var addServiceAccountCmd = &cobra.Command{
+	Use:   "add-service-account",
+	Short: "Add a Google service account for Workload Identity",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		m := gsaEmailPattern.FindStringSubmatch(gsaEmail)
+		if m == nil {
+			return fmt.Errorf("invalid service account email '%s', expected <name>@<project>.iam.gserviceaccount.com", gsaEmail)
+		}
+		// The workload pool belongs to the cluster's project, which is usually the account's
+		project := gsaProject
+		if project == "" {
+			project = m[1]
+		}
+
+		cmd.PrintErrln("Adding Google service account...")
+		id, err := AppAPI.AddServiceAccount(gsaName, gsaEmail)
+		if err != nil {
+			return err
+		}
+
+		cmd.PrintErrln("Service account added successfully: ", id)
+		fmt.Fprintln(cmd.OutOrStdout(), id)
+		cmd.PrintErrln("Complete the Workload Identity binding with:")
+		fmt.Fprintln(cmd.OutOrStdout(), workloadIdentityBinding(gsaEmail, project, AppAPI.Namespace, gsaK8sAccount))
+		return nil
+	},
+}
+
+var delServiceAccountCmd = &cobra.Command{
+	Use:   "del-service-account",
+	Short: "Delete a Google service account for Workload Identity",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.PrintErrln("Deleting Google service account...")
+		if err := AppAPI.DeleteServiceAccount(gsaID); err != nil {
+			return err
+		}
+
+		cmd.PrintErrln("Service account deleted successfully: ", gsaID)
+		return nil
+	},
+}
+
+var listServiceAccountCmd = &cobra.Command{
+	Use:   "list-service-account",
+	Short: "List Google service accounts for Workload Identity",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		accounts, err := AppAPI.ListServiceAccount()
+		if err != nil {
+			return err
+		}
+		cmd.PrintErrln("Listing Google service accounts...")
+		if len(accounts) == 0 {
+			cmd.Println("No service accounts found.")
+			return nil
+		}
+
+		for _, account := range accounts {
+			fmt.Fprintf(cmd.OutOrStdout(), "Account ID: %s, Name: %s, Email: %s\n", account.ID, account.DisplayName, account.Email)
+		}
+		return nil
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(gkeCmd)
+	gkeCmd.AddCommand(addServiceAccountCmd, delServiceAccountCmd, listServiceAccountCmd)
+
+	addServiceAccountCmd.Flags().StringVar(&gsaName, "name", "", "Display name of the service account")
+	addServiceAccountCmd.Flags().StringVar(&gsaEmail, "email", "", "Service account email (<name>@<project>.iam.gserviceaccount.com)")
+	addServiceAccountCmd.Flags().StringVar(&gsaProject, "project", "", "Project of the GKE cluster (default: the service account's project)")
+	addServiceAccountCmd.Flags().StringVar(&gsaK8sAccount, "k8s-service-account", "ingext", "Kubernetes service account of the Ingext pods")
+	_ = addServiceAccountCmd.MarkFlagRequired("name")
+	_ = addServiceAccountCmd.MarkFlagRequired("email")
+
+	delServiceAccountCmd.Flags().StringVar(&gsaID, "id", "", "Service account ID")
+	_ = delServiceAccountCmd.MarkFlagRequired("id")
+}
@@ -0,0 +1,334 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	model "github.com/SecurityDo/ingext_api/model"
+	"github.com/spf13/cobra"
+)
+
+var graphOutput string
+
+// Example usage:
+// 1. ingext stream graph
+// 2. ingext stream graph --output dot | dot -Tpng > pipeline.png
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Show the source -> router -> processor -> sink topology",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if graphOutput != "tree" && graphOutput != "dot" && graphOutput != "mermaid" {
+			return fmt.Errorf("invalid output '%s', must be one of: tree, dot, mermaid", graphOutput)
+		}
+
+		sources, err := AppAPI.ListDataSource()
+		if err != nil {
+			return err
+		}
+		sinks, err := AppAPI.ListDataSink()
+		if err != nil {
+			return err
+		}
+		routers, err := AppAPI.ListRouter()
+		if err != nil {
+			return err
+		}
+
+		g := newStreamGraph(sources, sinks, routers)
+		switch graphOutput {
+		case "dot":
+			g.writeDot(cmd.OutOrStdout())
+		case "mermaid":
+			g.writeMermaid(cmd.OutOrStdout())
+		default:
+			g.writeTree(cmd.OutOrStdout())
+		}
+
+		if n := len(g.orphanSources()) + len(g.orphanSinks()); n > 0 {
+			cmd.PrintErrf("Warning: %d sources or sinks are not connected to any router.\n", n)
+		}
+		return nil
+	},
+}
+
+// streamGraph indexes the stream objects by ID. Routers are the edges:
+// each one connects its sources, through an optional processor, to its sinks.
+type streamGraph struct {
+	sources []*model.DataSourceConfig
+	sinks   []*model.DataSinkConfig
+	routers []*model.RouterConfig
+
+	sourceByID map[string]*model.DataSourceConfig
+	sinkByID   map[string]*model.DataSinkConfig
+	routed     map[string]bool // source and sink IDs referenced by a router
+}
+
+func newStreamGraph(sources []*model.DataSourceConfig, sinks []*model.DataSinkConfig, routers []*model.RouterConfig) *streamGraph {
+	g := &streamGraph{
+		sources:    sources,
+		sinks:      sinks,
+		routers:    routers,
+		sourceByID: make(map[string]*model.DataSourceConfig),
+		sinkByID:   make(map[string]*model.DataSinkConfig),
+		routed:     make(map[string]bool),
+	}
+	for _, s := range sources {
+		g.sourceByID[s.ID] = s
+	}
+	for _, s := range sinks {
+		g.sinkByID[s.ID] = s
+	}
+	for _, r := range routers {
+		for _, id := range r.Sources {
+			g.routed[id] = true
+		}
+		for _, id := range r.Sinks {
+			g.routed[id] = true
+		}
+	}
+	return g
+}
+
+func (g *streamGraph) orphanSources() []*model.DataSourceConfig {
+	var orphans []*model.DataSourceConfig
+	for _, s := range g.sources {
+		if !g.routed[s.ID] {
+			orphans = append(orphans, s)
+		}
+	}
+	return orphans
+}
+
+func (g *streamGraph) orphanSinks() []*model.DataSinkConfig {
+	var orphans []*model.DataSinkConfig
+	for _, s := range g.sinks {
+		if !g.routed[s.ID] {
+			orphans = append(orphans, s)
+		}
+	}
+	return orphans
+}
+
+// unattachedRouters returns the routers reading from no source known to the platform
+func (g *streamGraph) unattachedRouters() []*model.RouterConfig {
+	var routers []*model.RouterConfig
+	for _, r := range g.routers {
+		attached := false
+		for _, id := range r.Sources {
+			if _, ok := g.sourceByID[id]; ok {
+				attached = true
+				break
+			}
+		}
+		if !attached {
+			routers = append(routers, r)
+		}
+	}
+	return routers
+}
+
+// routersOf returns the routers reading from the source
+func (g *streamGraph) routersOf(sourceID string) []*model.RouterConfig {
+	var routers []*model.RouterConfig
+	for _, r := range g.routers {
+		for _, id := range r.Sources {
+			if id == sourceID {
+				routers = append(routers, r)
+				break
+			}
+		}
+	}
+	return routers
+}
+
+func (g *streamGraph) sourceLabel(id string) string {
+	if s, ok := g.sourceByID[id]; ok {
+		return fmt.Sprintf("%s (%s, %s)", s.Name, s.ID, s.Type)
+	}
+	return fmt.Sprintf("%s (missing)", id)
+}
+
+func (g *streamGraph) sinkLabel(id string) string {
+	if s, ok := g.sinkByID[id]; ok {
+		return fmt.Sprintf("%s (%s, %s)", s.Name, s.ID, s.Type)
+	}
+	return fmt.Sprintf("%s (missing)", id)
+}
+
+// writeTree renders one tree per source:
+//
+//	source clickstream (src-1, hec)
+//	└── router main (r-1)
+//	    └── processor filter-logic
+//	        └── sink s3-archive (k-1, s3)
+func (g *streamGraph) writeTree(w io.Writer) {
+	for _, s := range g.sources {
+		routers := g.routersOf(s.ID)
+		if len(routers) == 0 {
+			fmt.Fprintf(w, "source %s  [ORPHAN: no router]\n", g.sourceLabel(s.ID))
+			continue
+		}
+		fmt.Fprintf(w, "source %s\n", g.sourceLabel(s.ID))
+		for i, r := range routers {
+			last := i == len(routers)-1
+			fmt.Fprintf(w, "%s router %s (%s)\n", treeBranch(last), r.Name, r.ID)
+			g.writeRouterTree(w, r, treeIndent(last))
+		}
+	}
+
+	// Routers reading from no known source would not show up under any of them
+	if unattached := g.unattachedRouters(); len(unattached) > 0 {
+		fmt.Fprintln(w)
+		for _, r := range unattached {
+			reason := "no source"
+			if len(r.Sources) > 0 {
+				reason = "missing source " + strings.Join(r.Sources, ", ")
+			}
+			fmt.Fprintf(w, "router %s (%s)  [UNATTACHED: %s]\n", r.Name, r.ID, reason)
+			g.writeRouterTree(w, r, "")
+		}
+	}
+
+	if orphans := g.orphanSinks(); len(orphans) > 0 {
+		fmt.Fprintln(w)
+		for _, s := range orphans {
+			fmt.Fprintf(w, "sink %s  [ORPHAN: no router]\n", g.sinkLabel(s.ID))
+		}
+	}
+}
+
+// writeRouterTree writes the processor and sinks of the router below it
+func (g *streamGraph) writeRouterTree(w io.Writer, r *model.RouterConfig, indent string) {
+	if r.Processor != "" {
+		fmt.Fprintf(w, "%s%s processor %s\n", indent, treeBranch(true), r.Processor)
+		indent += treeIndent(true)
+	}
+	if len(r.Sinks) == 0 {
+		fmt.Fprintf(w, "%s%s (no sink)\n", indent, treeBranch(true))
+	}
+	for j, id := range r.Sinks {
+		fmt.Fprintf(w, "%s%s sink %s\n", indent, treeBranch(j == len(r.Sinks)-1), g.sinkLabel(id))
+	}
+}
+
+func treeBranch(last bool) string {
+	if last {
+		return "└──"
+	}
+	return "├──"
+}
+
+func treeIndent(last bool) string {
+	if last {
+		return "    "
+	}
+	return "│   "
+}
+
+// graphEdges walks the routers and calls edge for every connection, using the
+// node keys "source:<id>", "router:<id>", "processor:<router id>" and "sink:<id>".
+// Processors are keyed by router, so a script shared by two routers doesn't merge their paths.
+func (g *streamGraph) graphEdges(edge func(from, to string)) {
+	for _, r := range g.routers {
+		router := "router:" + r.ID
+		for _, id := range r.Sources {
+			edge("source:"+id, router)
+		}
+		last := router
+		if r.Processor != "" {
+			last = "processor:" + r.ID
+			edge(router, last)
+		}
+		for _, id := range r.Sinks {
+			edge(last, "sink:"+id)
+		}
+	}
+}
+
+// graphNodes returns every node key with its label and whether it's an orphan
+func (g *streamGraph) graphNodes() (keys []string, labels map[string]string, orphan map[string]bool) {
+	labels = make(map[string]string)
+	orphan = make(map[string]bool)
+	add := func(key, label string) {
+		if _, ok := labels[key]; !ok {
+			keys = append(keys, key)
+			labels[key] = label
+		}
+	}
+
+	for _, s := range g.sources {
+		add("source:"+s.ID, "source "+g.sourceLabel(s.ID))
+		orphan["source:"+s.ID] = !g.routed[s.ID]
+	}
+	for _, r := range g.routers {
+		add("router:"+r.ID, fmt.Sprintf("router %s (%s)", r.Name, r.ID))
+		if r.Processor != "" {
+			add("processor:"+r.ID, "processor "+r.Processor)
+		}
+		// IDs referenced by a router but unknown to the platform are still drawn
+		for _, id := range r.Sources {
+			add("source:"+id, "source "+g.sourceLabel(id))
+		}
+		for _, id := range r.Sinks {
+			add("sink:"+id, "sink "+g.sinkLabel(id))
+		}
+	}
+	for _, s := range g.sinks {
+		add("sink:"+s.ID, "sink "+g.sinkLabel(s.ID))
+		orphan["sink:"+s.ID] = !g.routed[s.ID]
+	}
+	return keys, labels, orphan
+}
+
+func (g *streamGraph) writeDot(w io.Writer) {
+	keys, labels, orphan := g.graphNodes()
+
+	fmt.Fprintln(w, "digraph ingext {")
+	fmt.Fprintln(w, "  rankdir=LR;")
+	fmt.Fprintln(w, "  node [shape=box];")
+	for _, key := range keys {
+		attrs := fmt.Sprintf("label=%q", labels[key])
+		if orphan[key] {
+			attrs += ", color=red, style=dashed"
+		}
+		fmt.Fprintf(w, "  %q [%s];\n", key, attrs)
+	}
+	g.graphEdges(func(from, to string) {
+		fmt.Fprintf(w, "  %q -> %q;\n", from, to)
+	})
+	fmt.Fprintln(w, "}")
+}
+
+func (g *streamGraph) writeMermaid(w io.Writer) {
+	keys, labels, orphan := g.graphNodes()
+
+	// Mermaid node IDs can't contain most punctuation, so number them
+	ids := make(map[string]string, len(keys))
+	for i, key := range keys {
+		ids[key] = fmt.Sprintf("n%d", i)
+	}
+
+	fmt.Fprintln(w, "flowchart LR")
+	var orphans []string
+	for _, key := range keys {
+		label := strings.ReplaceAll(labels[key], `"`, "#quot;")
+		fmt.Fprintf(w, "  %s[\"%s\"]\n", ids[key], label)
+		if orphan[key] {
+			orphans = append(orphans, ids[key])
+		}
+	}
+	g.graphEdges(func(from, to string) {
+		fmt.Fprintf(w, "  %s --> %s\n", ids[from], ids[to])
+	})
+	if len(orphans) > 0 {
+		fmt.Fprintln(w, "  classDef orphan stroke:#d33,stroke-width:2px,stroke-dasharray:5 5")
+		fmt.Fprintf(w, "  class %s orphan\n", strings.Join(orphans, ","))
+	}
+}
+
+func init() {
+	streamCmd.AddCommand(graphCmd)
+
+	graphCmd.Flags().StringVarP(&graphOutput, "output", "o", "tree", "Output format (tree|dot|mermaid)")
+}
@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	model "github.com/SecurityDo/ingext_api/model"
+)
+
+func TestWriteTree(t *testing.T) {
+	sources := []*model.DataSourceConfig{
+		{ID: "src-1", Name: "clickstream", Type: "hec"},
+		{ID: "src-2", Name: "audit", Type: "webhook"},
+	}
+	sinks := []*model.DataSinkConfig{
+		{ID: "k-1", Name: "s3-archive", Type: "s3"},
+		{ID: "k-2", Name: "unused", Type: "s3"},
+	}
+	routers := []*model.RouterConfig{
+		{ID: "r-1", Name: "main", Processor: "filter-logic", Sources: []string{"src-1", "src-9"}, Sinks: []string{"k-1", "k-9"}},
+		{ID: "r-2", Name: "draft", Sinks: []string{"k-1"}},
+		{ID: "r-3", Name: "stale", Processor: "old", Sources: []string{"src-8"}},
+	}
+
+	var b strings.Builder
+	newStreamGraph(sources, sinks, routers).writeTree(&b)
+
+	want := `source clickstream (src-1, hec)
+└── router main (r-1)
+    └── processor filter-logic
+        ├── sink s3-archive (k-1, s3)
+        └── sink k-9 (missing)
+source audit (src-2, webhook)  [ORPHAN: no router]
+
+router draft (r-2)  [UNATTACHED: no source]
+└── sink s3-archive (k-1, s3)
+router stale (r-3)  [UNATTACHED: missing source src-8]
+└── processor old
+    └── (no sink)
+
+sink unused (k-2, s3)  [ORPHAN: no router]
+`
+	if got := b.String(); got != want {
+		t.Errorf("writeTree() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -1,24 +1,37 @@
 package commands
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"text/tabwriter"
+
+	"ingext/internal/secrets"
 
 	"github.com/SecurityDo/ingext_api/model"
 	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
 )
 
 var (
-	integType       string
-	integName       string
-	integDesc       string
-	integID         string
-	configParams    map[string]string
-	configIntParams map[string]int64
-	configJsonFlags []string
-	secretParams    map[string]string
+	integType        string
+	integName        string
+	integDesc        string
+	integID          string
+	integTest        bool
+	integFromFile    string
+	integResolve     bool
+	configParams     map[string]string
+	configIntParams  map[string]int64
+	configBoolParams map[string]string // pflag has no string-to-bool flag, parsed in getConfigMap
+	configJsonFlags  []string
+	secretParams     map[string]string
 )
 
 var integrationCmd = &cobra.Command{
@@ -26,35 +39,139 @@ var integrationCmd = &cobra.Command{
 	Short: "Manage integrations",
 }
 
-func getSecretRaw() (raw json.RawMessage, err error) {
-	// This function is a placeholder for the actual implementation
-	// that retrieves the raw JSON configuration.
-	// For now, it returns an empty JSON object.
-	secret := make(map[string]interface{})
+// getSecretMap collects the --secret flags. Values can be literal or refer to a
+// secret source, see secrets.Resolve: @file, file:, env:, stdin:, exec:, k8s:, literal:
+func getSecretMap() (secret map[string]interface{}, err error) {
+	secret = make(map[string]interface{})
 	for key, value := range secretParams {
-		if len(value) > 1 && value[0] == '@' {
-			filePath := value[1:] // Remove '@'
+		resolved, err := secrets.Resolve(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve secret '%s': %w", key, err)
+		}
+		secret[key] = resolved
+	}
+	return secret, nil
+}
 
-			content, err := os.ReadFile(filePath)
+// resolveK8sSecret reads "namespace/secret/key", or "secret/key" in the ingext namespace,
+// from the cluster of the active profile.
+func resolveK8sSecret(ref string) (string, error) {
+	parts := strings.Split(ref, "/")
+	switch len(parts) {
+	case 2:
+		return AppAPI.GetK8sSecret("", parts[0], parts[1])
+	case 3:
+		return AppAPI.GetK8sSecret(parts[0], parts[1], parts[2])
+	}
+	return "", fmt.Errorf("invalid reference '%s', expected namespace/secret/key", ref)
+}
+
+// integrationFile is the --from-file format, the shape of model.Integration
+// with config and secret as objects. "type" is accepted as an alias of "integration".
+type integrationFile struct {
+	Name        string                 `json:"name"`
+	Integration string                 `json:"integration"`
+	Type        string                 `json:"type"`
+	Description string                 `json:"description"`
+	Config      map[string]interface{} `json:"config"`
+	Secret      map[string]interface{} `json:"secret"`
+}
+
+// envReference matches ${VAR} and ${VAR:-default}
+var envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// expandEnv replaces the ${VAR} references of s from the environment, adding
+// the variables that aren't set and have no default to missing
+func expandEnv(s string, missing *[]string) string {
+	return envReference.ReplaceAllStringFunc(s, func(ref string) string {
+		m := envReference.FindStringSubmatch(ref)
+		if value, ok := os.LookupEnv(m[1]); ok {
+			return value
+		}
+		if m[2] != "" {
+			return m[3]
+		}
+		*missing = append(*missing, m[1])
+		return ref
+	})
+}
+
+// expandEnvValues applies expandEnv to the strings of a parsed document, at any depth
+func expandEnvValues(v interface{}, missing *[]string) interface{} {
+	switch t := v.(type) {
+	case string:
+		return expandEnv(t, missing)
+	case map[string]interface{}:
+		for k, val := range t {
+			t[k] = expandEnvValues(val, missing)
+		}
+	case []interface{}:
+		for i, val := range t {
+			t[i] = expandEnvValues(val, missing)
+		}
+	}
+	return v
+}
+
+// loadIntegrationFile reads a YAML or JSON integration definition, expanding
+// ${VAR} references from the environment in its string values. Since the file may
+// come from someone else, secret values only use the secret sources of --secret
+// (@file, env:, exec:, k8s:, ...) with resolveSources, otherwise they are an error.
+func loadIntegrationFile(path string, resolveSources bool) (*integrationFile, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file '%s': %w", path, err)
+	}
+
+	// Expanding after parsing keeps values containing YAML syntax (": ", " #") intact
+	file := &integrationFile{}
+	if err := yaml.UnmarshalStrict(raw, file); err != nil {
+		return nil, fmt.Errorf("invalid integration file '%s': %w", path, err)
+	}
+	var missing []string
+	for _, field := range []*string{&file.Name, &file.Integration, &file.Type, &file.Description} {
+		*field = expandEnv(*field, &missing)
+	}
+	expandEnvValues(file.Config, &missing)
+	expandEnvValues(file.Secret, &missing)
+	if len(missing) > 0 {
+		slices.Sort(missing)
+		return nil, fmt.Errorf("environment variables not set in '%s': %s", path, strings.Join(slices.Compact(missing), ", "))
+	}
+
+	if file.Integration == "" {
+		file.Integration = file.Type
+	}
+	for k, v := range file.Secret {
+		if str, ok := v.(string); ok {
+			if !resolveSources && secrets.IsReference(str) {
+				return nil, fmt.Errorf("secret '%s' in '%s' reads a secret source, use --resolve-secrets to allow it (or literal: for a literal value)", k, path)
+			}
+			resolved, err := secrets.Resolve(str)
 			if err != nil {
-				return nil, fmt.Errorf("failed to read file for key '%s': %w", key, err)
+				return nil, fmt.Errorf("failed to resolve secret '%s': %w", k, err)
 			}
-
-			// Replace the file path with the actual file content
-			secret[key] = string(content)
-		} else {
-			secret[key] = value
+			file.Secret[k] = resolved
 		}
 	}
-	b, _ := json.Marshal(secret)
-	return b, nil
+	return file, nil
+}
+
+// configValue is one key=value given by a --config* flag
+type configValue struct {
+	flag  string
+	key   string
+	value interface{}
 }
-func getConfigRaw() (raw json.RawMessage, err error) {
-	// This function is a placeholder for the actual implementation
-	// that retrieves the raw JSON configuration.
-	// For now, it returns an empty JSON object.
-	config := make(map[string]interface{})
-	for key, value := range configParams {
+
+// getConfigMap collects the --config, --config-int, --config-bool and --config-json flags.
+// Dotted keys set nested values (auth.mode=oauth gives {"auth":{"mode":"oauth"}}).
+// Setting the same key, or a key and one of its parents, from several flags is an error
+// rather than letting one silently win.
+func getConfigMap() (config map[string]interface{}, err error) {
+	var values []configValue
+	for _, key := range sortedKeys(configParams) {
+		value := configParams[key]
 		if len(value) > 1 && value[0] == '@' {
 			filePath := value[1:] // Remove '@'
 
@@ -64,13 +181,20 @@ func getConfigRaw() (raw json.RawMessage, err error) {
 			}
 
 			// Replace the file path with the actual file content
-			config[key] = string(content)
+			values = append(values, configValue{"--config", key, string(content)})
 		} else {
-			config[key] = value
+			values = append(values, configValue{"--config", key, value})
 		}
 	}
-	for k, v := range configIntParams {
-		config[k] = v
+	for _, key := range sortedKeys(configIntParams) {
+		values = append(values, configValue{"--config-int", key, configIntParams[key]})
+	}
+	for _, key := range sortedKeys(configBoolParams) {
+		b, err := strconv.ParseBool(configBoolParams[key])
+		if err != nil {
+			return nil, fmt.Errorf("invalid bool value '%s' for key '%s'", configBoolParams[key], key)
+		}
+		values = append(values, configValue{"--config-bool", key, b})
 	}
 	// Iterate over raw flags: ["config={\"a\":1}", "retries=3", "debug=true"]
 	for _, flag := range configJsonFlags {
@@ -89,22 +213,86 @@ func getConfigRaw() (raw json.RawMessage, err error) {
 			return nil, fmt.Errorf("failed to parse JSON for key '%s': %w", key, err)
 		}
 
-		config[key] = typedValue
+		values = append(values, configValue{"--config-json", key, typedValue})
+	}
+
+	// 3. Detect conflicts: a path can only be set once, and not together with its parents
+	for i, v := range values {
+		if v.key == "" || strings.HasPrefix(v.key, ".") || strings.HasSuffix(v.key, ".") || strings.Contains(v.key, "..") {
+			return nil, fmt.Errorf("invalid config key '%s' (%s)", v.key, v.flag)
+		}
+		for _, prev := range values[:i] {
+			if prev.key == v.key {
+				return nil, fmt.Errorf("config key '%s' is set by both %s and %s", v.key, prev.flag, v.flag)
+			}
+			if strings.HasPrefix(v.key, prev.key+".") || strings.HasPrefix(prev.key, v.key+".") {
+				return nil, fmt.Errorf("config keys '%s' (%s) and '%s' (%s) overlap", prev.key, prev.flag, v.key, v.flag)
+			}
+		}
 	}
 
-	b, _ := json.Marshal(config)
+	config = make(map[string]interface{})
+	for _, v := range values {
+		setPath(config, strings.Split(v.key, "."), v.value)
+	}
+	return config, nil
+}
 
+// setPath sets a nested value, creating the intermediate objects
+func setPath(m map[string]interface{}, path []string, value interface{}) {
+	for _, key := range path[:len(path)-1] {
+		child, ok := m[key].(map[string]interface{})
+		if !ok {
+			child = make(map[string]interface{})
+			m[key] = child
+		}
+		m = child
+	}
+	m[path[len(path)-1]] = value
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// mergeRaw overlays the given keys onto a raw JSON object, keeping the keys not overridden
+func mergeRaw(existing json.RawMessage, overrides map[string]interface{}) (json.RawMessage, error) {
+	merged := make(map[string]interface{})
+	if len(existing) > 0 && string(existing) != "null" {
+		if err := json.Unmarshal(existing, &merged); err != nil {
+			return nil, fmt.Errorf("existing value is not a JSON object: %w", err)
+		}
+	}
+	mergeMaps(merged, overrides)
+	b, _ := json.Marshal(merged)
 	return b, nil
 }
 
+// mergeMaps deep merges src into dst: nested objects are merged key by key,
+// any other value in src replaces the one in dst.
+func mergeMaps(dst, src map[string]interface{}) {
+	for k, v := range src {
+		srcChild, srcIsMap := v.(map[string]interface{})
+		dstChild, dstIsMap := dst[k].(map[string]interface{})
+		if srcIsMap && dstIsMap {
+			mergeMaps(dstChild, srcChild)
+			continue
+		}
+		dst[k] = v
+	}
+}
+
 //return json.RawMessage("{}")
 
 var integrationAddCmd = &cobra.Command{
 	Use:   "add",
 	Short: "Add an integration",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		cmd.PrintErrf("Adding integration %s of type %s\n", integName, integType)
-
 		entry := &model.Integration{
 			Integration: integType,
 			Name:        integName,
@@ -112,16 +300,51 @@ var integrationAddCmd = &cobra.Command{
 			//ConfigParameters:   configParams,
 			//SecretParameters:   secretParams,
 		}
-		configRaw, err := getConfigRaw()
+		config := make(map[string]interface{})
+		secret := make(map[string]interface{})
+
+		// The file is the base, flags override individual fields and keys
+		if integFromFile != "" {
+			file, err := loadIntegrationFile(integFromFile, integResolve)
+			if err != nil {
+				return err
+			}
+			if !cmd.Flags().Changed("integration") {
+				entry.Integration = file.Integration
+			}
+			if !cmd.Flags().Changed("name") {
+				entry.Name = file.Name
+			}
+			if !cmd.Flags().Changed("description") {
+				entry.Description = file.Description
+			}
+			mergeMaps(config, file.Config)
+			mergeMaps(secret, file.Secret)
+		}
+		if entry.Integration == "" {
+			return fmt.Errorf("integration type is required, use --integration or set 'integration' in --from-file")
+		}
+		if entry.Name == "" {
+			return fmt.Errorf("name is required, use --name or set 'name' in --from-file")
+		}
+
+		cmd.PrintErrf("Adding integration %s of type %s\n", entry.Name, entry.Integration)
+
+		flagConfig, err := getConfigMap()
 		if err != nil {
 			return fmt.Errorf("failed to get config: %w", err)
 		}
-		secretRaw, err := getSecretRaw()
+		flagSecret, err := getSecretMap()
 		if err != nil {
 			return fmt.Errorf("failed to get config: %w", err)
 		}
-		entry.Config = configRaw
-		entry.Secret = secretRaw
+		mergeMaps(config, flagConfig)
+		mergeMaps(secret, flagSecret)
+		if err := validateIntegration(cmd, entry.Integration, config, secret, false); err != nil {
+			return err
+		}
+		entry.Config, _ = json.Marshal(config)
+		entry.Secret, _ = json.Marshal(secret)
 		id, err := AppAPI.AddIntegration(entry)
 		if err != nil {
 			return err
@@ -129,10 +352,39 @@ var integrationAddCmd = &cobra.Command{
 
 		cmd.PrintErrln("Integration added successfully: ", id)
 		cmd.Println(id)
+
+		if integTest {
+			if err := runIntegrationTest(cmd, id); err != nil {
+				return fmt.Errorf("integration %s was created but %w", id, err)
+			}
+		}
 		return nil
 	},
 }
 
+var integrationTestCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Check the connectivity and credentials of an integration",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runIntegrationTest(cmd, integID)
+	},
+}
+
+// runIntegrationTest reports the result of the platform side check and fails if it didn't pass
+func runIntegrationTest(cmd *cobra.Command, id string) error {
+	cmd.PrintErrf("Testing integration %s...\n", id)
+	result, err := AppAPI.TestIntegration(id)
+	if err != nil {
+		return err
+	}
+	if !result.Success {
+		cmd.PrintErrln("Integration test failed:", result.Message)
+		return fmt.Errorf("failed the connection test: %s", result.Message)
+	}
+	cmd.PrintErrln("Integration test passed.", result.Message)
+	return nil
+}
+
 var integrationDelCmd = &cobra.Command{
 	Use:   "del",
 	Short: "Delete an integration",
@@ -164,30 +416,342 @@ var integrationListCmd = &cobra.Command{
 		}
 
 		for _, entry := range entries {
-			cmd.PrintErrf("ID: %s, Name: %s, Integration: %s, Description: %s\n", entry.ID, entry.Name, entry.Integration, entry.Description)
+			fmt.Fprintf(cmd.OutOrStdout(), "ID: %s, Name: %s, Integration: %s, Description: %s\n", entry.ID, entry.Name, entry.Integration, entry.Description)
+		}
+		return nil
+	},
+}
+
+var integrationGetCmd = &cobra.Command{
+	Use:   "get",
+	Short: "Show an integration (secrets masked)",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entry, err := AppAPI.GetIntegration(integID)
+		if err != nil {
+			return err
+		}
+
+		config := "{}"
+		if len(entry.Config) > 0 {
+			var buf bytes.Buffer
+			if err := json.Indent(&buf, entry.Config, "", "  "); err == nil {
+				config = buf.String()
+			} else {
+				config = string(entry.Config)
+			}
+		}
+
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "ID\t%s\n", entry.ID)
+		fmt.Fprintf(w, "Name\t%s\n", entry.Name)
+		fmt.Fprintf(w, "Integration\t%s\n", entry.Integration)
+		fmt.Fprintf(w, "Description\t%s\n", entry.Description)
+		if len(entry.Secret) > 0 {
+			fmt.Fprintf(w, "Secret\t%s\n", maskSecret(entry.Secret))
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Config:\n%s\n", config)
+		return nil
+	},
+}
+
+// Example usage:
+// ingext integration update --id <id> --config channel=#alerts --secret token=@token.txt
+var integrationUpdateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Update an integration in place, keeping its ID",
+	Long: `Merges --config, --config-int, --config-json and --secret into the existing
+integration. Keys that are not given keep their current value, and the ID stays
+the same so plugin sources referencing the integration keep working.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entry, err := AppAPI.GetIntegration(integID)
+		if err != nil {
+			return err
+		}
+
+		if cmd.Flags().Changed("name") {
+			entry.Name = integName
+		}
+		if cmd.Flags().Changed("description") {
+			entry.Description = integDesc
+		}
+
+		config, err := getConfigMap()
+		if err != nil {
+			return fmt.Errorf("failed to get config: %w", err)
+		}
+		if entry.Config, err = mergeRaw(entry.Config, config); err != nil {
+			return fmt.Errorf("failed to merge config: %w", err)
+		}
+		secret, err := getSecretMap()
+		if err != nil {
+			return fmt.Errorf("failed to get secret: %w", err)
+		}
+		if entry.Secret, err = mergeRaw(entry.Secret, secret); err != nil {
+			return fmt.Errorf("failed to merge secret: %w", err)
+		}
+
+		// Only the keys given are checked, what the server already stores is its business
+		if err := validateIntegration(cmd, entry.Integration, config, secret, true); err != nil {
+			return err
+		}
+
+		cmd.PrintErrf("Updating integration %s\n", integID)
+		if err := AppAPI.UpdateIntegration(entry); err != nil {
+			return err
+		}
+		cmd.PrintErrln("Integration updated successfully: ", integID)
+		return nil
+	},
+}
+
+var integrationTypesCmd = &cobra.Command{
+	Use:   "types",
+	Short: "List available integration types",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		types, err := AppAPI.ListIntegrationType()
+		if err != nil {
+			return err
+		}
+		for _, t := range types {
+			fmt.Fprintf(cmd.OutOrStdout(), "Integration: %s, Description: %s\n", t.Name, t.Description)
 		}
 		return nil
 	},
 }
 
+var integrationDescribeCmd = &cobra.Command{
+	Use:               "describe <type>",
+	Short:             "Show the config and secret parameters of an integration type",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeIntegrationTypes,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		types, err := AppAPI.ListIntegrationType()
+		if err != nil {
+			return err
+		}
+		t := findIntegrationType(types, args[0])
+		if t == nil {
+			return fmt.Errorf("unknown integration type '%s', see 'ingext integration types'", args[0])
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "%s: %s\n\n", t.Name, t.Description)
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "PARAMETER\tFLAG\tTYPE\tREQUIRED\tDEFAULT\tDESCRIPTION")
+		for _, p := range t.Parameters {
+			flag := "--config"
+			if p.Secret {
+				flag = "--secret"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%t\t%s\t%s\n", p.Name, flag, p.Type, p.Required, string(p.Default), p.Description)
+		}
+		return w.Flush()
+	},
+}
+
+func findIntegrationType(types []*model.IntegrationType, name string) *model.IntegrationType {
+	for _, t := range types {
+		if t.Name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// validateIntegration checks config and secret against the parameter schema of the
+// integration type: required parameters, unknown keys and value types. Parameter names
+// may be dotted paths of nested values (auth.mode). With partial, config and secret are
+// only the keys being changed, so missing required parameters aren't reported.
+// If the schema can't be fetched, the check is skipped and the server decides.
+func validateIntegration(cmd *cobra.Command, integration string, config, secret map[string]interface{}, partial bool) error {
+	types, err := AppAPI.ListIntegrationType()
+	if err != nil {
+		cmd.PrintErrf("Warning: unable to validate integration '%s': %v\n", integration, err)
+		return nil
+	}
+	t := findIntegrationType(types, integration)
+	if t == nil {
+		return fmt.Errorf("unknown integration type '%s', see 'ingext integration types'", integration)
+	}
+
+	if problems := integrationProblems(t, config, secret, partial); len(problems) > 0 {
+		return fmt.Errorf("invalid %s integration (see 'ingext integration describe %s'):\n  %s",
+			integration, integration, strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// integrationProblems lists, sorted, what doesn't match the parameters of t
+func integrationProblems(t *model.IntegrationType, config, secret map[string]interface{}, partial bool) []string {
+	var problems []string
+	known := map[string]map[string]bool{"config": {}, "secret": {}}
+	for _, p := range t.Parameters {
+		kind, values := "config", config
+		if p.Secret {
+			kind, values = "secret", secret
+		}
+		known[kind][p.Name] = true
+
+		v, ok := lookupPath(values, strings.Split(p.Name, "."))
+		if !ok {
+			if p.Required && len(p.Default) == 0 && !partial {
+				problems = append(problems, fmt.Sprintf("missing required %s parameter '%s'", kind, p.Name))
+			}
+			continue
+		}
+		if !matchesParameterType(p.Type, v) {
+			problems = append(problems, fmt.Sprintf("%s parameter '%s' must be of type %s", kind, p.Name, p.Type))
+		}
+	}
+	problems = append(problems, unknownParameters("config", config, "", known["config"])...)
+	problems = append(problems, unknownParameters("secret", secret, "", known["secret"])...)
+	sort.Strings(problems)
+	return problems
+}
+
+// lookupPath returns the value at a path of nested objects
+func lookupPath(values map[string]interface{}, path []string) (interface{}, bool) {
+	var v interface{} = values
+	for _, key := range path {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if v, ok = m[key]; !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// unknownParameters reports the keys of values, at any depth, that are neither a known
+// parameter nor the parent of one. The value of a known parameter isn't looked into.
+func unknownParameters(kind string, values map[string]interface{}, prefix string, known map[string]bool) []string {
+	var problems []string
+	for k, v := range values {
+		path := prefix + k
+		if known[path] {
+			continue
+		}
+		child, isMap := v.(map[string]interface{})
+		if isMap && hasParameterUnder(known, path) {
+			problems = append(problems, unknownParameters(kind, child, path+".", known)...)
+			continue
+		}
+		problems = append(problems, fmt.Sprintf("unknown %s parameter '%s'", kind, path))
+	}
+	return problems
+}
+
+func hasParameterUnder(known map[string]bool, path string) bool {
+	for name := range known {
+		if strings.HasPrefix(name, path+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesParameterType checks a flag value against a schema type. Values come from
+// --config (string), --config-int (int64) or --config-json (any JSON type).
+func matchesParameterType(paramType string, v interface{}) bool {
+	switch paramType {
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "int", "integer":
+		switch n := v.(type) {
+		case int64:
+			return true
+		case float64:
+			return n == float64(int64(n))
+		}
+		return false
+	case "number":
+		switch v.(type) {
+		case int64, float64:
+			return true
+		}
+		return false
+	case "bool", "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "object":
+		_, ok := v.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := v.([]interface{})
+		return ok
+	}
+	// Unknown or free-form (json) types are left to the server
+	return true
+}
+
+// completeIntegrationTypes provides shell completion for integration type names
+func completeIntegrationTypes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	if err := initCompletionAPI(); err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	types, err := AppAPI.ListIntegrationType()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var names []string
+	for _, t := range types {
+		if strings.HasPrefix(t.Name, toComplete) {
+			names = append(names, t.Name+"\t"+t.Description)
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
 func init() {
+	secrets.Register("k8s", secrets.ResolverFunc(resolveK8sSecret))
+
 	RootCmd.AddCommand(integrationCmd)
-	integrationCmd.AddCommand(integrationAddCmd, integrationDelCmd)
+	integrationCmd.AddCommand(integrationAddCmd, integrationDelCmd, integrationListCmd)
+	integrationCmd.AddCommand(integrationGetCmd, integrationUpdateCmd)
+	integrationCmd.AddCommand(integrationTypesCmd, integrationDescribeCmd, integrationTestCmd)
 
 	// Flags
-	integrationAddCmd.Flags().StringVar(&integType, "integration", "", "Integration type")
+	integrationAddCmd.Flags().StringVar(&integType, "integration", "", "Integration type (see 'ingext integration types')")
 	integrationAddCmd.Flags().StringVar(&integName, "name", "", "Name")
 	integrationAddCmd.Flags().StringVar(&integDesc, "description", "", "Description")
 
-	integrationAddCmd.Flags().StringToStringVarP(&configParams, "config", "", nil, "Configuration string type parameters")
+	integrationAddCmd.Flags().StringToStringVarP(&configParams, "config", "", nil, "Configuration string type parameters (dotted keys set nested values, e.g. auth.mode=oauth)")
 	integrationAddCmd.Flags().StringToInt64VarP(&configIntParams, "config-int", "", nil, "Configuration int type parameters")
+	integrationAddCmd.Flags().StringToStringVarP(&configBoolParams, "config-bool", "", nil, "Configuration bool type parameters")
 	integrationAddCmd.Flags().StringArrayVar(&configJsonFlags, "config-json", []string{}, "Set JSON values (e.g. key=[1,2])")
-	integrationAddCmd.Flags().StringToStringVarP(&secretParams, "secret", "", nil, "Secret parameters")
+	integrationAddCmd.Flags().StringToStringVarP(&secretParams, "secret", "", nil, "Secret parameters, value can be literal, @file, env:VAR, stdin:, exec:cmd, k8s:namespace/secret/key or literal:value")
 
-	_ = integrationAddCmd.MarkFlagRequired("integration")
-	_ = integrationAddCmd.MarkFlagRequired("name")
+	integrationAddCmd.Flags().StringVarP(&integFromFile, "from-file", "f", "", "YAML or JSON file with name, integration, description, config and secret (flags override its values)")
+	integrationAddCmd.Flags().BoolVar(&integResolve, "resolve-secrets", false, "Resolve secret sources (@file, env:, exec:, k8s:, ...) in the secrets of --from-file")
+	integrationAddCmd.Flags().BoolVar(&integTest, "test", false, "Test connectivity and credentials after adding")
+	_ = integrationAddCmd.RegisterFlagCompletionFunc("integration", completeIntegrationTypes)
 
 	integrationDelCmd.Flags().StringVar(&integID, "id", "", "Integration id")
 	_ = integrationDelCmd.MarkFlagRequired("id")
 
+	integrationTestCmd.Flags().StringVar(&integID, "id", "", "Integration id")
+	_ = integrationTestCmd.MarkFlagRequired("id")
+
+	integrationGetCmd.Flags().StringVar(&integID, "id", "", "Integration id")
+	_ = integrationGetCmd.MarkFlagRequired("id")
+
+	integrationUpdateCmd.Flags().StringVar(&integID, "id", "", "Integration id")
+	integrationUpdateCmd.Flags().StringVar(&integName, "name", "", "Name")
+	integrationUpdateCmd.Flags().StringVar(&integDesc, "description", "", "Description")
+	integrationUpdateCmd.Flags().StringToStringVarP(&configParams, "config", "", nil, "Configuration string type parameters (dotted keys set nested values, e.g. auth.mode=oauth)")
+	integrationUpdateCmd.Flags().StringToInt64VarP(&configIntParams, "config-int", "", nil, "Configuration int type parameters")
+	integrationUpdateCmd.Flags().StringToStringVarP(&configBoolParams, "config-bool", "", nil, "Configuration bool type parameters")
+	integrationUpdateCmd.Flags().StringArrayVar(&configJsonFlags, "config-json", []string{}, "Set JSON values (e.g. key=[1,2])")
+	integrationUpdateCmd.Flags().StringToStringVarP(&secretParams, "secret", "", nil, "Secret parameters, value can be literal, @file, env:VAR, stdin:, exec:cmd, k8s:namespace/secret/key or literal:value")
+	_ = integrationUpdateCmd.MarkFlagRequired("id")
+
 }
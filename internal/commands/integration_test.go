@@ -0,0 +1,355 @@
+package commands
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/SecurityDo/ingext_api/model"
+)
+
+func TestGetConfigMap(t *testing.T) {
+	tests := []struct {
+		name    string
+		str     map[string]string
+		ints    map[string]int64
+		bools   map[string]string
+		json    []string
+		want    map[string]interface{}
+		wantErr string
+	}{
+		{
+			name:  "flat keys of every type",
+			str:   map[string]string{"url": "https://x"},
+			ints:  map[string]int64{"port": 8443},
+			bools: map[string]string{"verify": "true"},
+			json:  []string{`tags=["a","b"]`},
+			want:  map[string]interface{}{"url": "https://x", "port": int64(8443), "verify": true, "tags": []interface{}{"a", "b"}},
+		},
+		{
+			name:  "dotted keys share their parents",
+			str:   map[string]string{"auth.mode": "oauth"},
+			ints:  map[string]int64{"auth.port": 8443},
+			bools: map[string]string{"tls.verify": "false"},
+			want: map[string]interface{}{
+				"auth": map[string]interface{}{"mode": "oauth", "port": int64(8443)},
+				"tls":  map[string]interface{}{"verify": false},
+			},
+		},
+		{
+			name: "value with an equal sign",
+			json: []string{`filter={"q":"a=b"}`},
+			want: map[string]interface{}{"filter": map[string]interface{}{"q": "a=b"}},
+		},
+		{
+			name:    "same key from two flags",
+			str:     map[string]string{"port": "x"},
+			ints:    map[string]int64{"port": 1},
+			wantErr: "config key 'port' is set by both --config and --config-int",
+		},
+		{
+			name:    "key and its parent",
+			str:     map[string]string{"auth": "x"},
+			bools:   map[string]string{"auth.enabled": "true"},
+			wantErr: "config keys 'auth' (--config) and 'auth.enabled' (--config-bool) overlap",
+		},
+		{
+			name:    "parent set as JSON",
+			str:     map[string]string{"auth.mode": "oauth"},
+			json:    []string{`auth={"mode":"basic"}`},
+			wantErr: "config keys 'auth.mode' (--config) and 'auth' (--config-json) overlap",
+		},
+		{
+			name:    "empty path element",
+			str:     map[string]string{"auth..mode": "oauth"},
+			wantErr: "invalid config key 'auth..mode'",
+		},
+		{
+			name:    "trailing dot",
+			ints:    map[string]int64{"auth.": 1},
+			wantErr: "invalid config key 'auth.'",
+		},
+		{
+			name:    "invalid bool",
+			bools:   map[string]string{"verify": "maybe"},
+			wantErr: "invalid bool value 'maybe' for key 'verify'",
+		},
+		{
+			name:    "invalid JSON",
+			json:    []string{"tags=[a"},
+			wantErr: "failed to parse JSON for key 'tags'",
+		},
+		{
+			name:    "JSON flag without a value",
+			json:    []string{"tags"},
+			wantErr: "invalid format 'tags'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configParams, configIntParams, configBoolParams, configJsonFlags = tt.str, tt.ints, tt.bools, tt.json
+			t.Cleanup(func() {
+				configParams, configIntParams, configBoolParams, configJsonFlags = nil, nil, nil, nil
+			})
+
+			got, err := getConfigMap()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("getConfigMap() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("getConfigMap() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getConfigMap() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("INGEXT_TEST_A", "a")
+	t.Setenv("INGEXT_TEST_EMPTY", "")
+	t.Setenv("INGEXT_TEST_YAML", "x: y #z")
+
+	tests := []struct {
+		in          string
+		want        string
+		wantMissing []string
+	}{
+		{in: "plain", want: "plain"},
+		{in: "${INGEXT_TEST_A}", want: "a"},
+		{in: "pre-${INGEXT_TEST_A}-${INGEXT_TEST_A}", want: "pre-a-a"},
+		{in: "${INGEXT_TEST_YAML}", want: "x: y #z"},
+		{in: "${INGEXT_TEST_UNSET:-dflt}", want: "dflt"},
+		{in: "${INGEXT_TEST_UNSET:-}", want: ""},
+		// A set but empty variable wins over the default
+		{in: "${INGEXT_TEST_EMPTY:-dflt}", want: ""},
+		{in: "${INGEXT_TEST_UNSET}", want: "${INGEXT_TEST_UNSET}", wantMissing: []string{"INGEXT_TEST_UNSET"}},
+		{in: "$INGEXT_TEST_A ${1BAD}", want: "$INGEXT_TEST_A ${1BAD}"},
+	}
+	for _, tt := range tests {
+		var missing []string
+		if got := expandEnv(tt.in, &missing); got != tt.want || !reflect.DeepEqual(missing, tt.wantMissing) {
+			t.Errorf("expandEnv(%q) = %q, missing %v, want %q, missing %v", tt.in, got, missing, tt.want, tt.wantMissing)
+		}
+	}
+}
+
+func TestExpandEnvValues(t *testing.T) {
+	t.Setenv("INGEXT_TEST_A", "a")
+
+	doc := map[string]interface{}{
+		"s":      "${INGEXT_TEST_A}",
+		"n":      float64(3),
+		"b":      true,
+		"nested": map[string]interface{}{"list": []interface{}{"${INGEXT_TEST_A}", float64(1), "${INGEXT_TEST_UNSET}"}},
+		// Keys are not expanded
+		"${INGEXT_TEST_A}": "k",
+	}
+	want := map[string]interface{}{
+		"s":                "a",
+		"n":                float64(3),
+		"b":                true,
+		"nested":           map[string]interface{}{"list": []interface{}{"a", float64(1), "${INGEXT_TEST_UNSET}"}},
+		"${INGEXT_TEST_A}": "k",
+	}
+
+	var missing []string
+	expandEnvValues(doc, &missing)
+	if !reflect.DeepEqual(doc, want) {
+		t.Errorf("expandEnvValues() = %#v, want %#v", doc, want)
+	}
+	if !reflect.DeepEqual(missing, []string{"INGEXT_TEST_UNSET"}) {
+		t.Errorf("expandEnvValues() missing = %v, want [INGEXT_TEST_UNSET]", missing)
+	}
+}
+
+func TestMergeMaps(t *testing.T) {
+	tests := []struct {
+		name       string
+		file, flag map[string]interface{}
+		want       map[string]interface{}
+	}{
+		{
+			name: "flags override file values",
+			file: map[string]interface{}{"url": "a", "port": float64(1)},
+			flag: map[string]interface{}{"url": "b"},
+			want: map[string]interface{}{"url": "b", "port": float64(1)},
+		},
+		{
+			name: "nested objects are merged key by key",
+			file: map[string]interface{}{"auth": map[string]interface{}{"mode": "basic", "user": "u"}},
+			flag: map[string]interface{}{"auth": map[string]interface{}{"mode": "oauth"}},
+			want: map[string]interface{}{"auth": map[string]interface{}{"mode": "oauth", "user": "u"}},
+		},
+		{
+			name: "a flag value replaces a file object",
+			file: map[string]interface{}{"auth": map[string]interface{}{"mode": "basic"}},
+			flag: map[string]interface{}{"auth": "none"},
+			want: map[string]interface{}{"auth": "none"},
+		},
+		{
+			name: "a flag object replaces a file value",
+			file: map[string]interface{}{"auth": "none"},
+			flag: map[string]interface{}{"auth": map[string]interface{}{"mode": "oauth"}},
+			want: map[string]interface{}{"auth": map[string]interface{}{"mode": "oauth"}},
+		},
+		{
+			name: "arrays are replaced",
+			file: map[string]interface{}{"tags": []interface{}{"a", "b"}},
+			flag: map[string]interface{}{"tags": []interface{}{"c"}},
+			want: map[string]interface{}{"tags": []interface{}{"c"}},
+		},
+		{
+			name: "no file",
+			flag: map[string]interface{}{"url": "b"},
+			want: map[string]interface{}{"url": "b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// As in 'integration add': the file is the base, the flags are merged over it
+			got := make(map[string]interface{})
+			mergeMaps(got, tt.file)
+			mergeMaps(got, tt.flag)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("merged = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadIntegrationFile(t *testing.T) {
+	t.Setenv("INGEXT_TEST_PW", "abc #def")
+	t.Setenv("INGEXT_TEST_URL", "https://x: y")
+
+	tests := []struct {
+		name    string
+		content string
+		resolve bool
+		want    *integrationFile
+		wantErr string
+	}{
+		{
+			name:    "variables are expanded in values",
+			content: "name: siem\ntype: slack\nconfig:\n  url: ${INGEXT_TEST_URL}\nsecret:\n  pw: ${INGEXT_TEST_PW}\n",
+			want: &integrationFile{Name: "siem", Integration: "slack", Type: "slack",
+				Config: map[string]interface{}{"url": "https://x: y"}, Secret: map[string]interface{}{"pw": "abc #def"}},
+		},
+		{
+			name:    "missing variables are listed once",
+			content: "name: ${INGEXT_TEST_B}\nintegration: ${INGEXT_TEST_B}\ndescription: ${INGEXT_TEST_A}\n",
+			wantErr: "environment variables not set in 'FILE': INGEXT_TEST_A, INGEXT_TEST_B",
+		},
+		{
+			name:    "unknown field",
+			content: "name: siem\nintegraton: slack\n",
+			wantErr: "invalid integration file",
+		},
+		{
+			name:    "secret sources need --resolve-secrets",
+			content: "name: siem\nintegration: slack\nsecret:\n  token: file:/etc/passwd\n",
+			wantErr: "secret 'token' in 'FILE' reads a secret source, use --resolve-secrets",
+		},
+		{
+			name:    "@path needs --resolve-secrets",
+			content: "name: siem\nintegration: slack\nsecret:\n  token: \"@/etc/passwd\"\n",
+			wantErr: "use --resolve-secrets",
+		},
+		{
+			name:    "literal: values are unescaped",
+			content: "name: siem\nintegration: slack\nsecret:\n  token: literal:file:abc\n",
+			want:    &integrationFile{Name: "siem", Integration: "slack", Secret: map[string]interface{}{"token": "file:abc"}},
+		},
+		{
+			name:    "secret sources are resolved with --resolve-secrets",
+			content: "name: siem\nintegration: slack\nsecret:\n  token: env:INGEXT_TEST_PW\n",
+			resolve: true,
+			want:    &integrationFile{Name: "siem", Integration: "slack", Secret: map[string]interface{}{"token": "abc #def"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "integration.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			got, err := loadIntegrationFile(path, tt.resolve)
+			if tt.wantErr != "" {
+				wantErr := strings.ReplaceAll(tt.wantErr, "FILE", path)
+				if err == nil || !strings.Contains(err.Error(), wantErr) {
+					t.Fatalf("loadIntegrationFile() error = %v, want %q", err, wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loadIntegrationFile() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("loadIntegrationFile() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIntegrationProblems(t *testing.T) {
+	integrationType := &model.IntegrationType{Name: "siem", Parameters: []*model.IntegrationParameter{
+		{Name: "host", Type: "string", Required: true},
+		{Name: "port", Type: "int", Default: json.RawMessage(`514`)},
+		{Name: "auth.mode", Type: "string", Required: true},
+		{Name: "auth.retries", Type: "int"},
+		{Name: "labels", Type: "object"},
+		{Name: "token", Type: "string", Secret: true, Required: true},
+	}}
+	valid := func() map[string]interface{} {
+		return map[string]interface{}{"host": "h", "auth": map[string]interface{}{"mode": "basic"}}
+	}
+	token := map[string]interface{}{"token": "t"}
+
+	tests := []struct {
+		name    string
+		config  map[string]interface{}
+		secret  map[string]interface{}
+		partial bool
+		want    []string
+	}{
+		{name: "valid", config: valid(), secret: token},
+		{name: "free-form object", config: map[string]interface{}{"host": "h", "auth": map[string]interface{}{"mode": "basic"}, "labels": map[string]interface{}{"any": map[string]interface{}{"depth": 1}}}, secret: token},
+		{name: "missing", config: map[string]interface{}{"auth": map[string]interface{}{}}, want: []string{
+			"missing required config parameter 'auth.mode'",
+			"missing required config parameter 'host'",
+			"missing required secret parameter 'token'",
+		}},
+		{name: "nested type", config: map[string]interface{}{"host": "h", "auth": map[string]interface{}{"mode": "basic", "retries": "x"}}, secret: token, want: []string{
+			"config parameter 'auth.retries' must be of type int",
+		}},
+		{name: "nested unknown", config: map[string]interface{}{"host": "h", "auth": map[string]interface{}{"mode": "basic", "mdoe": "x"}, "other": map[string]interface{}{"a": 1}}, secret: token, want: []string{
+			"unknown config parameter 'auth.mdoe'",
+			"unknown config parameter 'other'",
+		}},
+		{name: "parent is not an object", config: map[string]interface{}{"host": "h", "auth": "basic"}, secret: token, want: []string{
+			"missing required config parameter 'auth.mode'",
+			"unknown config parameter 'auth'",
+		}},
+		{name: "partial skips required", config: map[string]interface{}{"auth": map[string]interface{}{"retries": float64(3)}}, partial: true},
+		{name: "partial checks what is given", config: map[string]interface{}{"port": "x"}, secret: map[string]interface{}{"host": "h"}, partial: true, want: []string{
+			"config parameter 'port' must be of type int",
+			"unknown secret parameter 'host'",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := integrationProblems(integrationType, tt.config, tt.secret, tt.partial)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("integrationProblems() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
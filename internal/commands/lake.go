@@ -2,7 +2,9 @@ package commands
 
 import (
 	"fmt"
+
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 var (
@@ -13,6 +15,15 @@ var (
 	lakeContainer string
 )
 
+func validateLakeStorage(storage string) error {
+	for _, s := range providerStorage {
+		if s == storage {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid storage '%s', must be one of: s3, blob, gcs", storage)
+}
+
 var lakeCmd = &cobra.Command{
 	Use:   "lake",
 	Short: "Manage data lake",
@@ -26,8 +37,16 @@ var lakeAddCmd = &cobra.Command{
 var lakeAddIndexCmd = &cobra.Command{
 	Use:   "index",
 	Short: "Add an index to the lake",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Default to the native object storage of the profile's provider
+		if !cmd.Flags().Changed("storage") {
+			lakeStorage = providerStorage[viper.GetString("provider")]
+		}
+		if err := validateLakeStorage(lakeStorage); err != nil {
+			return err
+		}
 		fmt.Printf("Adding lake index to storage %s (Bucket: %s)\n", lakeStorage, lakeBucket)
+		return nil
 	},
 }
 
@@ -37,7 +56,7 @@ func init() {
 	lakeAddCmd.AddCommand(lakeAddIndexCmd)
 
 	// Flags for 'lake add index'
-	lakeAddIndexCmd.Flags().StringVar(&lakeStorage, "storage", "", "Storage type (s3|blob|gcs)")
+	lakeAddIndexCmd.Flags().StringVar(&lakeStorage, "storage", "", "Storage type (s3|blob|gcs, default: the provider's storage)")
 	lakeAddIndexCmd.Flags().StringVar(&lakeBucket, "bucket", "", "Bucket name")
 	lakeAddIndexCmd.Flags().StringVar(&lakePrefix, "prefix", "", "Path prefix")
 	lakeAddIndexCmd.Flags().StringVar(&lakeAccount, "storageaccount", "", "Storage account")
@@ -0,0 +1,93 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"regexp"
+	"time"
+
+	"ingext/internal/api"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	logsFollow  bool
+	logsSince   time.Duration
+	logsGrep    string
+	logsNoColor bool
+)
+
+// logColors are the ANSI colors cycled through for the pod prefixes
+var logColors = []string{"\033[36m", "\033[33m", "\033[32m", "\033[35m", "\033[34m", "\033[31m", "\033[96m", "\033[93m"}
+
+const colorReset = "\033[0m"
+
+// isTerminal reports whether f is a character device, i.e. not a pipe or a file
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Example usage:
+// ingext logs api -f --since 10m --grep error
+var logsCmd = &cobra.Command{
+	Use:         "logs [component]",
+	Short:       "Stream the logs of the ingext pods, merged with a per-pod prefix",
+	Long:        "Streams the logs of all pods in the namespace, or of the pods of a component (matched by pod name prefix or app/component label). Containers waiting to restart show the log of their last run. With --follow, new pods and restarted containers are picked up until Ctrl-C.",
+	Args:        cobra.MaximumNArgs(1),
+	Annotations: map[string]string{clusterCommand: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts := api.LogOptions{Follow: logsFollow, Since: logsSince}
+		if len(args) > 0 {
+			opts.Component = args[0]
+		}
+
+		var grep *regexp.Regexp
+		if logsGrep != "" {
+			var err error
+			if grep, err = regexp.Compile(logsGrep); err != nil {
+				return fmt.Errorf("invalid --grep pattern: %w", err)
+			}
+		}
+		color := !logsNoColor && os.Getenv("NO_COLOR") == "" && isTerminal(os.Stdout)
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
+
+		lines := make(chan api.LogLine, 100)
+		errc := make(chan error, 1)
+		go func() {
+			errc <- AppAPI.StreamLogs(ctx, opts, lines)
+		}()
+
+		// Colors are assigned per pod in the order their first line arrives
+		prefixes := make(map[string]string)
+		for line := range lines {
+			if grep != nil && !grep.MatchString(line.Text) {
+				continue
+			}
+			key := line.Pod + "/" + line.Container
+			prefix, ok := prefixes[key]
+			if !ok {
+				prefix = fmt.Sprintf("[%s]", key)
+				if color {
+					prefix = logColors[len(prefixes)%len(logColors)] + prefix + colorReset
+				}
+				prefixes[key] = prefix
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), prefix, line.Text)
+		}
+		return <-errc
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(logsCmd)
+
+	logsCmd.Flags().BoolVarP(&logsFollow, "follow", "f", false, "Keep streaming new log lines")
+	logsCmd.Flags().DurationVar(&logsSince, "since", 0, "Only show logs newer than a relative duration like 10m or 1h (default: all)")
+	logsCmd.Flags().StringVar(&logsGrep, "grep", "", "Only show lines matching this regular expression")
+	logsCmd.Flags().BoolVar(&logsNoColor, "no-color", false, "Disable colored pod prefixes")
+}
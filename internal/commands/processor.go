@@ -1,16 +1,28 @@
 package commands
 
 import (
+	"bufio"
+	"bytes"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"ingext/internal/processor"
 
 	"github.com/spf13/cobra"
 )
 
 var (
-	procName string
-	procFile string
+	procName    string
+	procFile    string
+	procDir     string
+	procInput   string
+	procTimeout time.Duration
+	procVersion int
+	procForce   bool
 )
 
 var processorCmd = &cobra.Command{
@@ -27,50 +39,299 @@ var processorAddCmd = &cobra.Command{
 	// Example usage:
 	// 1. ingext processor add --name my-proc --file ./my-script.js
 	// 2. cat my-script.js | ingext processor add --name my-proc --file -
+	// 3. ingext processor add --dir ./proc/
 	RunE: func(cmd *cobra.Command, args []string) error {
-		var content []byte
-		var err error
+		src, err := loadProcessorSource(cmd)
+		if err != nil {
+			return err
+		}
+		content := src.content
 
-		// CHECK: Is the user asking to read from Stdin?
-		if procFile == "-" {
-			// Read from the pipe
-			content, err = io.ReadAll(cmd.InOrStdin())
-			if err != nil {
-				return fmt.Errorf("failed to read from stdin: %w", err)
+		// A bundle carries its own name, --name still wins
+		name := procName
+		if name == "" && src.bundle != nil {
+			name = src.bundle.Manifest.Name
+		}
+		if name == "" {
+			return fmt.Errorf("processor name is required, use --name or set 'name' in the manifest")
+		}
+
+		if issues := processor.Lint(src.name, content); len(issues) > 0 {
+			printLintIssues(cmd, src, issues)
+			if !procForce {
+				return fmt.Errorf("processor failed validation with %d issues, use --force to deploy anyway", len(issues))
 			}
+			cmd.PrintErrln("Warning: deploying despite validation issues (--force)")
+		}
+
+		// Now you have the content in 'content' variable
+		//  cmd.PrintErrln()
+		//  cmd.Printf( )  for output data/result
+		cmd.PrintErrf("Deploying processor '%s' (%d bytes)...\n", name, len(content))
+
+		version, err := AppAPI.AddProcessor(name, content)
+		if err != nil {
+			return err
+		}
+		cmd.PrintErrf("Processor '%s' deployed as version %d\n", name, version)
+		return nil
+	},
+}
+
+// processorSource is a processor script loaded with --file, or bundled from --dir
+type processorSource struct {
+	name    string // file path or directory, for messages
+	content []byte
+	bundle  *processor.Bundle // nil unless loaded from --dir
+}
+
+// loadProcessorSource loads the script selected by the --file or --dir flag
+func loadProcessorSource(cmd *cobra.Command) (*processorSource, error) {
+	if procDir == "" {
+		content, err := readProcessorFile(cmd, procFile)
+		if err != nil {
+			return nil, err
+		}
+		return &processorSource{name: procFile, content: content}, nil
+	}
+
+	bundle, err := processor.BundleDir(procDir)
+	if err != nil {
+		return nil, err
+	}
+	cmd.PrintErrf("Bundled processor '%s' version '%s' from %s (entry: %s)\n",
+		bundle.Manifest.Name, bundle.Manifest.Version, procDir, bundle.Manifest.Entry)
+	return &processorSource{name: procDir, content: bundle.Content, bundle: bundle}, nil
+}
+
+// readProcessorFile loads a processor script from a file path, or from stdin when path is "-"
+func readProcessorFile(cmd *cobra.Command, path string) (content []byte, err error) {
+	// CHECK: Is the user asking to read from Stdin?
+	if path == "-" {
+		// Read from the pipe
+		content, err = io.ReadAll(cmd.InOrStdin())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read from stdin: %w", err)
+		}
+	} else {
+		// Read from the file path provided
+		content, err = os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file '%s': %w", path, err)
+		}
+	}
+	return content, nil
+}
+
+// Example usage:
+// ingext processor test --file ./scripts/filter.js --input samples.ndjson > output.ndjson
+var processorTestCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Run a processor locally against sample events",
+	Annotations: map[string]string{
+		localCommand: "true",
+	},
+	Long: `Runs the processor script locally, without a cluster, against newline-delimited
+JSON events. Transformed events are written to stdout as NDJSON, dropped events
+and errors are reported on stderr. Exits non-zero if any event fails.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if procDir == "" && procFile == "-" && procInput == "-" {
+			return fmt.Errorf("--file and --input can't both be read from stdin")
+		}
+		src, err := loadProcessorSource(cmd)
+		if err != nil {
+			return err
+		}
+		if len(src.content) == 0 {
+			return fmt.Errorf("processor content is empty")
+		}
+
+		runner, err := processor.NewRunner(src.name, src.content, cmd.ErrOrStderr(), procTimeout)
+		if err != nil {
+			return err
+		}
+
+		var input io.Reader
+		if procInput == "-" {
+			input = cmd.InOrStdin()
 		} else {
-			// Read from the file path provided
-			content, err = os.ReadFile(procFile)
+			f, err := os.Open(procInput)
 			if err != nil {
-				return fmt.Errorf("failed to read file '%s': %w", procFile, err)
+				return fmt.Errorf("failed to read file '%s': %w", procInput, err)
 			}
+			defer f.Close()
+			input = f
 		}
 
-		if len(content) == 0 {
-			return fmt.Errorf("processor content is empty")
+		var in, out, dropped, failed int
+		scanner := bufio.NewScanner(input)
+		scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+		for line := 1; scanner.Scan(); line++ {
+			event := bytes.TrimSpace(scanner.Bytes())
+			if len(event) == 0 {
+				continue
+			}
+			in++
+
+			output, err := runner.Run(event)
+			if err != nil {
+				failed++
+				cmd.PrintErrf("line %d: error: %v\n", line, err)
+				continue
+			}
+			if len(output) == 0 {
+				dropped++
+				cmd.PrintErrf("line %d: dropped\n", line)
+				continue
+			}
+			for _, e := range output {
+				fmt.Fprintln(cmd.OutOrStdout(), string(e))
+				out++
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return fmt.Errorf("failed to read events: %w", err)
 		}
 
-		// Now you have the content in 'content' variable
-		//  cmd.PrintErrln()
-		//  cmd.Printf( )  for output data/result
-		cmd.PrintErrf("Deploying processor '%s' (%d bytes)...\n", procName, len(content))
+		cmd.PrintErrf("%d events in, %d out, %d dropped, %d errors\n", in, out, dropped, failed)
+		if failed > 0 {
+			return fmt.Errorf("processor failed on %d events", failed)
+		}
+		return nil
+	},
+}
 
-		// Call your global API
-		// return AppAPI.AddProcessor(procName, content)
+// Example usage:
+// ingext processor lint --file ./scripts/filter.js
+var processorLintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Validate a processor script without deploying it",
+	Annotations: map[string]string{
+		localCommand: "true",
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		src, err := loadProcessorSource(cmd)
+		if err != nil {
+			return err
+		}
+		issues := processor.Lint(src.name, src.content)
+		if len(issues) > 0 {
+			printLintIssues(cmd, src, issues)
+			return fmt.Errorf("processor failed validation with %d issues", len(issues))
+		}
+		cmd.PrintErrf("%s: OK\n", src.name)
+		return nil
+	},
+}
+
+// printLintIssues reports issues in the file:line:col format editors understand.
+// Lines of a bundle are mapped back to the file they were included from.
+func printLintIssues(cmd *cobra.Command, src *processorSource, issues []processor.Issue) {
+	for _, issue := range issues {
+		if src.bundle != nil && issue.Line > 0 {
+			if file, line := src.bundle.Origin(issue.Line); file != "" {
+				cmd.PrintErrf("%s:%d:%d: %s\n", filepath.Join(src.name, file), line, issue.Column, issue.Message)
+				continue
+			}
+		}
+		cmd.PrintErrf("%s: %s\n", src.name, issue)
+	}
+}
+
+var processorHistoryCmd = &cobra.Command{
+	Use:   "history",
+	Short: "List the deployed versions of a processor",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		versions, err := AppAPI.ListProcessorHistory(procName)
+		if err != nil {
+			return err
+		}
+		if len(versions) == 0 {
+			cmd.PrintErrln("No version found.")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tCREATED\tHASH\tAUTHOR")
+		for _, v := range versions {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", v.Version, v.CreatedAt.Format(time.RFC3339), v.ContentHash, v.Author)
+		}
+		return w.Flush()
+	},
+}
+
+var processorRollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "Make a previous version of a processor current again",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.PrintErrf("Rolling back processor '%s' to version %d...\n", procName, procVersion)
+		if err := AppAPI.RollbackProcessor(procName, procVersion); err != nil {
+			return err
+		}
+		cmd.PrintErrln("Processor rolled back successfully.")
+		return nil
+	},
+}
+
+// Example usage:
+// ingext processor diff --name filter --file ./scripts/filter.js
+var processorDiffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Compare the deployed processor with a local file",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		local, err := readProcessorFile(cmd, procFile)
+		if err != nil {
+			return err
+		}
+		deployed, err := AppAPI.GetProcessor(procName)
+		if err != nil {
+			return err
+		}
+
+		diff := processor.Diff(fmt.Sprintf("%s (deployed v%d)", procName, deployed.Version), procFile, []byte(deployed.Content), local)
+		if diff == "" {
+			cmd.PrintErrln("No differences.")
+			return nil
+		}
+		fmt.Fprint(cmd.OutOrStdout(), diff)
 		return nil
 	},
 }
 
 // ingext processor add --name filter --file ./scripts/filter.js
-// echo "function process() { ... }" | ingext processor add --name filter --file -
+// echo "function process(event) { ... }" | ingext processor add --name filter --file -
 func init() {
 	RootCmd.AddCommand(processorCmd)
-	processorCmd.AddCommand(processorAddCmd) // Add del similarly
+	processorCmd.AddCommand(processorAddCmd, processorTestCmd, processorLintCmd) // Add del similarly
+	processorCmd.AddCommand(processorHistoryCmd, processorRollbackCmd, processorDiffCmd)
 
 	//processorAddCmd.Flags().StringVar(&procName, "name", "", "Processor name")
 	//processorAddCmd.Flags().StringVar(&procFile, "file", "", "Processor file path")
 
-	processorAddCmd.Flags().StringVar(&procName, "name", "", "Processor name")
-	processorAddCmd.Flags().StringVar(&procFile, "file", "", "Processor file path (use '-' for stdin)")
-	_ = processorAddCmd.MarkFlagRequired("file")
+	processorAddCmd.Flags().StringVar(&procName, "name", "", "Processor name (defaults to the manifest name with --dir)")
+	processorAddCmd.Flags().BoolVar(&procForce, "force", false, "Deploy even if validation fails")
+
+	// --file or --dir selects the script for add, lint and test
+	for _, c := range []*cobra.Command{processorAddCmd, processorLintCmd, processorTestCmd} {
+		c.Flags().StringVar(&procFile, "file", "", "Processor file path (use '-' for stdin)")
+		c.Flags().StringVar(&procDir, "dir", "", "Processor directory with a manifest, bundled with its @include files")
+		c.MarkFlagsMutuallyExclusive("file", "dir")
+		c.MarkFlagsOneRequired("file", "dir")
+	}
+
+	processorHistoryCmd.Flags().StringVar(&procName, "name", "", "Processor name")
+	_ = processorHistoryCmd.MarkFlagRequired("name")
+
+	processorRollbackCmd.Flags().StringVar(&procName, "name", "", "Processor name")
+	processorRollbackCmd.Flags().IntVar(&procVersion, "to", 0, "Version to roll back to (see 'processor history')")
+	_ = processorRollbackCmd.MarkFlagRequired("name")
+	_ = processorRollbackCmd.MarkFlagRequired("to")
+
+	processorDiffCmd.Flags().StringVar(&procName, "name", "", "Processor name")
+	processorDiffCmd.Flags().StringVar(&procFile, "file", "", "Local processor file path (use '-' for stdin)")
+	_ = processorDiffCmd.MarkFlagRequired("name")
+	_ = processorDiffCmd.MarkFlagRequired("file")
+
+	processorTestCmd.Flags().StringVar(&procInput, "input", "-", "Sample events, one JSON event per line (use '-' for stdin)")
+	processorTestCmd.Flags().DurationVar(&procTimeout, "timeout", 5*time.Second, "Max processing time per event")
 }
@@ -0,0 +1,86 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"ingext/internal/config"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// providerCommand is the annotation marking command groups that only work on one
+// provider, e.g. 'eks' for AWS pod identity
+const providerCommand = "provider"
+
+// providerStorage lists the supported providers with the lake storage each uses by default
+var providerStorage = map[string]string{
+	"eks": "s3",
+	"aks": "blob",
+	"gke": "gcs",
+}
+
+func providerNames() []string {
+	names := make([]string, 0, len(providerStorage))
+	for name := range providerStorage {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func validateProvider(provider string) error {
+	if _, ok := providerStorage[provider]; !ok {
+		return fmt.Errorf("invalid provider '%s', must be one of: %s", provider, strings.Join(providerNames(), ", "))
+	}
+	return nil
+}
+
+// selectedProvider returns the profile selected by --cluster, or the current cluster
+// when not given, and its provider
+func selectedProvider() (clusterName, provider string) {
+	clusterName = cluster
+	if clusterName == "" {
+		clusterName = viper.GetString("current-cluster")
+	}
+	provider = viper.GetString("clusters." + clusterName + ".provider")
+	if clusterName == "" || provider == "" {
+		provider = viper.GetString("provider")
+	}
+	return clusterName, provider
+}
+
+// hideOtherProviders hides the command groups of the providers the selected profile doesn't use
+func hideOtherProviders() {
+	_, current := selectedProvider()
+	for _, c := range RootCmd.Commands() {
+		if p, ok := c.Annotations[providerCommand]; ok {
+			c.Hidden = p != current
+		}
+	}
+}
+
+// checkProvider rejects commands of a group that belongs to another provider than the selected profile's
+func checkProvider(cmd *cobra.Command) error {
+	clusterName, current := selectedProvider()
+	for c := cmd; c != nil; c = c.Parent() {
+		if p, ok := c.Annotations[providerCommand]; ok && p != current {
+			return fmt.Errorf("'%s' commands need a cluster with provider '%s', the profile of cluster '%s' uses '%s'", c.Name(), p, clusterName, current)
+		}
+	}
+	return nil
+}
+
+func init() {
+	// 'ingext --help' prints the help without running the initializers
+	defaultHelp := RootCmd.HelpFunc()
+	RootCmd.SetHelpFunc(func(cmd *cobra.Command, args []string) {
+		if viper.ConfigFileUsed() == "" {
+			config.InitConfig()
+		}
+		hideOtherProviders()
+		defaultHelp(cmd, args)
+	})
+}
@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func TestValidateProvider(t *testing.T) {
+	for _, p := range []string{"eks", "aks", "gke"} {
+		if err := validateProvider(p); err != nil {
+			t.Errorf("validateProvider(%q) error = %v", p, err)
+		}
+	}
+	for _, p := range []string{"", "EKS", "aws", "gcp"} {
+		err := validateProvider(p)
+		if err == nil || !strings.Contains(err.Error(), "must be one of: aks, eks, gke") {
+			t.Errorf("validateProvider(%q) error = %v, want the list of providers", p, err)
+		}
+	}
+}
+
+func TestCheckProvider(t *testing.T) {
+	// A provider group with a subcommand, and a command of no provider
+	group := &cobra.Command{Use: "gke", Annotations: map[string]string{providerCommand: "gke"}}
+	sub := &cobra.Command{Use: "list-service-account"}
+	group.AddCommand(sub)
+	plain := &cobra.Command{Use: "status"}
+
+	tests := []struct {
+		name    string
+		flag    string // --cluster
+		cmd     *cobra.Command
+		wantErr string
+	}{
+		{name: "current profile of another provider", cmd: sub, wantErr: "'gke' commands need a cluster with provider 'gke', the profile of cluster 'ekstest' uses 'eks'"},
+		{name: "commands of no provider always run", cmd: plain},
+		{name: "--cluster selects the profile of the provider", flag: "gkeprod", cmd: sub},
+		{name: "--cluster on the group itself", flag: "gkeprod", cmd: group},
+		{name: "--cluster selects another profile", flag: "akstest", cmd: sub, wantErr: "'gke' commands need a cluster with provider 'gke', the profile of cluster 'akstest' uses 'aks'"},
+		{name: "unknown profile falls back to the current provider", flag: "other", cmd: sub, wantErr: "the profile of cluster 'other' uses 'eks'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Set("current-cluster", "ekstest")
+			viper.Set("provider", "eks")
+			viper.Set("clusters", map[string]interface{}{
+				"ekstest": map[string]interface{}{"provider": "eks"},
+				"akstest": map[string]interface{}{"provider": "aks"},
+				"gkeprod": map[string]interface{}{"provider": "gke"},
+			})
+			cluster = tt.flag
+			t.Cleanup(func() {
+				viper.Reset()
+				cluster = ""
+			})
+
+			err := checkProvider(tt.cmd)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("checkProvider() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("checkProvider() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
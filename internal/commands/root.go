@@ -2,6 +2,7 @@ package commands
 
 import (
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 
@@ -19,6 +20,16 @@ var (
 	namespace string
 )
 
+// Version is the CLI version, set at build time with
+// -ldflags "-X ingext/internal/commands.Version=v1.2.3"
+var Version = "dev"
+
+// localCommand is the annotation marking commands that run without connecting to a cluster
+const localCommand = "local"
+
+// clusterCommand is the annotation marking commands that only need Kubernetes, not the platform
+const clusterCommand = "cluster"
+
 /*
 Default behavior: Use cmd.PrintErrf (or cmd.PrintErrln) for everything (interactive prompts, status logs, errors).
 Exception: Use cmd.Printf (or cmd.Println) only when you are printing the final machine-readable output.
@@ -42,6 +53,11 @@ var RootCmd = &cobra.Command{
 			return nil
 		}
 
+		// Local-only commands (e.g. 'processor test') don't talk to the cluster
+		if cmd.Annotations[localCommand] == "true" {
+			return nil
+		}
+
 		// 1. SKIP logic for Help and Autocompletion
 		// Cobra adds a "help" command automatically.
 		// "__complete" is used during shell tab-completion.
@@ -49,12 +65,8 @@ var RootCmd = &cobra.Command{
 			return nil
 		}
 
-		// 2. Load values from Viper (which now holds flags + config file values)
-		clusterName := viper.GetString("cluster")
-		namespace := viper.GetString("namespace")
-		kubeCtx := viper.GetString("context")
-		if clusterName == "" {
-			return fmt.Errorf("cluster name is required. Run 'ingext config' or use --cluster")
+		if err := checkProvider(cmd); err != nil {
+			return err
 		}
 
 		// 1. Configure the Handler options
@@ -72,26 +84,61 @@ var RootCmd = &cobra.Command{
 		// 3. Create the Logger
 		logger := slog.New(handler)
 
-		// If context is empty in config, we can default to empty string
-		// (which means client-go uses the "current-context" from ~/.kube/config)
-		if kubeCtx == "" {
+		if cmd.Annotations[clusterCommand] == "true" {
+			return connectAppAPI(logger)
+		}
+		return initAppAPI(logger)
+	},
+}
 
-			// Optional: log a warning
-			logger.Warn("no kube-context specified in config, using current system default")
+// initAppAPI connects the global AppAPI to the cluster of the active profile.
+// It is shared by PersistentPreRunE and by shell completion functions, which
+// are not covered by the pre-run hook.
+func initAppAPI(logger *slog.Logger) error {
+	if err := connectAppAPI(logger); err != nil {
+		return err
+	}
+	if err := AppAPI.Login(); err != nil {
+		return fmt.Errorf("failed to initialize app API: %w", err)
+	}
+	return nil
+}
 
-		}
+// connectAppAPI creates AppAPI and connects it to the Kubernetes cluster of the active profile
+func connectAppAPI(logger *slog.Logger) error {
+	// Load values from Viper (which now holds flags + config file values)
+	clusterName := viper.GetString("cluster")
+	namespace := viper.GetString("namespace")
+	kubeCtx := viper.GetString("context")
+	if clusterName == "" {
+		return fmt.Errorf("cluster name is required. Run 'ingext config' or use --cluster")
+	}
 
-		// 4. Inject into your Client
-		// Now your client logs will go to Stderr, respecting the --verbose flag
-		AppAPI = api.NewClient(logger)
+	// If context is empty in config, we can default to empty string
+	// (which means client-go uses the "current-context" from ~/.kube/config)
+	if kubeCtx == "" {
 
-		// 3. Initialize the Global API
-		if err := AppAPI.Init(clusterName, namespace, kubeCtx); err != nil {
-			return fmt.Errorf("failed to initialize app API: %w", err)
-		}
+		// Optional: log a warning
+		logger.Warn("no kube-context specified in config, using current system default")
 
-		return nil
-	},
+	}
+
+	// Inject into your Client
+	// Now your client logs will go to Stderr, respecting the --verbose flag
+	AppAPI = api.NewClient(logger)
+
+	// Initialize the Global API
+	if err := AppAPI.Connect(clusterName, namespace, kubeCtx); err != nil {
+		return fmt.Errorf("failed to initialize app API: %w", err)
+	}
+
+	return nil
+}
+
+// initCompletionAPI initializes AppAPI for shell completion with a silent
+// logger, so nothing is written to the terminal while the user is typing.
+func initCompletionAPI() error {
+	return initAppAPI(slog.New(slog.NewTextHandler(io.Discard, nil)))
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -106,7 +153,13 @@ func Execute() {
 var verbose bool
 
 func init() {
-	cobra.OnInitialize(config.InitConfig)
+	// The provider groups are hidden once the profile is loaded. provider.go initializes
+	// first, so this can't be a separate initializer registered there.
+	cobra.OnInitialize(func() {
+		config.InitConfig()
+		hideOtherProviders()
+	})
+	RootCmd.Version = Version
 
 	// Define global flags
 	RootCmd.PersistentFlags().StringVar(&cluster, "cluster", "", "k8s cluster name")
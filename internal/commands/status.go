@@ -0,0 +1,110 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	statusSince     time.Duration
+	statusMaxEvents int
+	statusMaxUsage  float64
+)
+
+var statusCmd = &cobra.Command{
+	Use:         "status",
+	Short:       "Show the health of the ingext deployment in the namespace",
+	Long:        "Lists the deployments and statefulsets of the namespace with their ready replicas, restarts and images, the persistent volume claims with their usage, and recent warning events. Exits with an error when anything is degraded.",
+	Annotations: map[string]string{clusterCommand: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(cmd.Context(), 30*time.Second)
+		defer cancel()
+
+		status, err := AppAPI.GetClusterStatus(ctx, time.Now().Add(-statusSince))
+		if err != nil {
+			return err
+		}
+
+		var problems []string
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+
+		fmt.Fprintln(w, "WORKLOAD\tREADY\tRESTARTS\tIMAGES\tSTATUS")
+		if len(status.Workloads) == 0 {
+			problems = append(problems, fmt.Sprintf("no deployments or statefulsets found in namespace '%s'", AppAPI.Namespace))
+		}
+		for _, wl := range status.Workloads {
+			state := "OK"
+			if !wl.Healthy() {
+				state = "DEGRADED"
+				problems = append(problems, fmt.Sprintf("%s/%s has %d/%d ready replicas", wl.Kind, wl.Name, wl.Ready, wl.Desired))
+				for _, reason := range wl.Waiting {
+					problems = append(problems, fmt.Sprintf("%s/%s pod %s", wl.Kind, wl.Name, reason))
+				}
+			}
+			fmt.Fprintf(w, "%s/%s\t%d/%d\t%d\t%s\t%s\n", wl.Kind, wl.Name, wl.Ready, wl.Desired, wl.Restarts, strings.Join(wl.Images, ","), state)
+		}
+
+		if len(status.Volumes) > 0 {
+			fmt.Fprintln(w)
+			fmt.Fprintln(w, "VOLUME\tPHASE\tSIZE\tUSED\tSTATUS")
+		}
+		for _, v := range status.Volumes {
+			state, used := "OK", "-"
+			if usage := v.Usage(); usage >= 0 {
+				used = fmt.Sprintf("%.0f%%", usage*100)
+				if usage >= statusMaxUsage/100 {
+					state = "DEGRADED"
+					problems = append(problems, fmt.Sprintf("volume %s is %s full", v.Name, used))
+				}
+			}
+			if v.Phase != "Bound" {
+				state = "DEGRADED"
+				problems = append(problems, fmt.Sprintf("volume %s is %s", v.Name, v.Phase))
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", v.Name, v.Phase, v.Size, used, state)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+
+		// Warning events are context for the problems above, they don't make the status degraded
+		if len(status.Events) > 0 {
+			events := status.Events
+			if statusMaxEvents > 0 && len(events) > statusMaxEvents {
+				events = events[:statusMaxEvents]
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "\nWarning events in the last %s:\n", statusSince)
+			w = tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "AGE\tOBJECT\tREASON\tCOUNT\tMESSAGE")
+			for _, e := range events {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n", time.Since(e.Time).Truncate(time.Second), e.Object, e.Reason, e.Count, strings.TrimSpace(e.Message))
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+		}
+
+		if len(problems) > 0 {
+			cmd.PrintErrln()
+			for _, p := range problems {
+				cmd.PrintErrln(" -", p)
+			}
+			return fmt.Errorf("ingext is degraded: %d problem(s) found", len(problems))
+		}
+		cmd.PrintErrln("\nAll components are healthy.")
+		return nil
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(statusCmd)
+
+	statusCmd.Flags().DurationVar(&statusSince, "since", time.Hour, "Show warning events seen within this duration")
+	statusCmd.Flags().IntVar(&statusMaxEvents, "max-events", 20, "Maximum number of warning events to show (0 for all)")
+	statusCmd.Flags().Float64Var(&statusMaxUsage, "max-volume-usage", 90, "Volume usage percentage above which a volume is degraded")
+}
@@ -1,7 +1,17 @@
 package commands
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"ingext/internal/api"
 
 	model "github.com/SecurityDo/ingext_api/model"
 	"github.com/spf13/cobra"
@@ -13,8 +23,116 @@ var (
 	dataFormat      string
 	dataCompression string
 	integrationID   string // For associating with an integration
+	sourceID        string
+	showSecret      bool
+
+	// Type specific source settings
+	s3Bucket   string
+	s3Prefix   string
+	s3SQSQueue string
+	hecIndex   string
+
+	// Flags for 'stream send'
+	sendFile      string
+	sendBatchSize int
+	sendRate      float64
+	sendRetries   int
+	sendGzip      bool
+
+	// Flags for 'stream tail'
+	tailSource   string
+	tailSink     string
+	tailRouter   string
+	tailLimit    int
+	tailFilters  []string
+	tailInterval time.Duration
 )
 
+// sourceTypeSupport lists the data formats and compressions accepted by each
+// source type. An empty compression means the data is sent uncompressed.
+var sourceTypeSupport = map[string]struct {
+	formats      []string
+	compressions []string
+}{
+	"plugin":  {formats: []string{"json"}, compressions: []string{""}},
+	"s3":      {formats: []string{"json", "ndjson", "csv", "syslog", "text"}, compressions: []string{"", "gzip", "zstd"}},
+	"hec":     {formats: []string{"json"}, compressions: []string{"", "gzip"}},
+	"webhook": {formats: []string{"json", "ndjson", "csv", "syslog", "text"}, compressions: []string{"", "gzip"}},
+}
+
+// normalizeCompression maps the "none" shown in help and errors to the empty compression
+func normalizeCompression(compression string) string {
+	if compression == "none" {
+		return ""
+	}
+	return compression
+}
+
+// validateSourceFormat checks that the source type exists and supports the format and compression.
+func validateSourceFormat(sourceType, format, compression string) error {
+	support, ok := sourceTypeSupport[sourceType]
+	if !ok {
+		return fmt.Errorf("unsupported source type '%s', must be one of: plugin, s3, hec, webhook", sourceType)
+	}
+	if !slices.Contains(support.formats, format) {
+		return fmt.Errorf("format '%s' is not supported by %s sources, must be one of: %s",
+			format, sourceType, strings.Join(support.formats, ", "))
+	}
+	if !slices.Contains(support.compressions, compression) {
+		var names []string
+		for _, c := range support.compressions {
+			if c == "" {
+				c = "none"
+			}
+			names = append(names, c)
+		}
+		return fmt.Errorf("compression '%s' is not supported by %s sources, must be one of: %s",
+			compression, sourceType, strings.Join(names, ", "))
+	}
+	return nil
+}
+
+// applySourceTypeFlags copies the type specific flags into the source config,
+// rejecting flags that don't belong to the source type.
+func applySourceTypeFlags(cmd *cobra.Command, source *model.DataSourceConfig) error {
+	flags := cmd.Flags()
+	for _, name := range []string{"s3-bucket", "s3-prefix", "sqs-queue"} {
+		if flags.Changed(name) && source.Type != "s3" {
+			return fmt.Errorf("--%s can only be used with s3 sources", name)
+		}
+	}
+	if flags.Changed("hec-index") && source.Type != "hec" {
+		return fmt.Errorf("--hec-index can only be used with hec sources")
+	}
+
+	switch source.Type {
+	case "s3":
+		if source.S3 == nil {
+			source.S3 = &model.S3SourceConfig{}
+		}
+		if flags.Changed("s3-bucket") {
+			source.S3.Bucket = s3Bucket
+		}
+		if flags.Changed("s3-prefix") {
+			source.S3.Prefix = s3Prefix
+		}
+		if flags.Changed("sqs-queue") {
+			source.S3.SQSQueue = s3SQSQueue
+		}
+		if source.S3.Bucket == "" {
+			return fmt.Errorf("s3-bucket is required for s3 source type")
+		}
+	case "hec":
+		if flags.Changed("hec-index") {
+			if source.HEC == nil {
+				source.HEC = &model.HECSourceConfig{}
+			}
+			source.HEC.Index = hecIndex
+		}
+	}
+	return nil
+}
+
 var streamCmd = &cobra.Command{
 	Use:   "stream",
 	Short: "Manage streams",
@@ -24,14 +142,21 @@ var addSourceCmd = &cobra.Command{
 	Use:   "add-source",
 	Short: "Add a stream source",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		fmt.Println("Adding stream datasource...")
+		cmd.PrintErrln("Adding stream datasource...")
+
+		dataCompression = normalizeCompression(dataCompression)
+		if err := validateSourceFormat(sourceType, dataFormat, dataCompression); err != nil {
+			return err
+		}
 
 		source := &model.DataSourceConfig{
-			Type: sourceType,
-			Name: resourceName,
-			// Add other necessary fields for DataSourceConfig
-			Format: "json", // Example format, adjust as needed
-			//Compression
+			Type:        sourceType,
+			Name:        resourceName,
+			Format:      dataFormat,
+			Compression: dataCompression,
+		}
+		if err := applySourceTypeFlags(cmd, source); err != nil {
+			return err
 		}
 
 		if sourceType == "plugin" {
@@ -61,6 +186,329 @@ var addSourceCmd = &cobra.Command{
 	},
 }
 
+var listSourceCmd = &cobra.Command{
+	Use:   "list-source",
+	Short: "List stream sources",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sources, err := AppAPI.ListDataSource()
+		if err != nil {
+			return err
+		}
+		if len(sources) == 0 {
+			cmd.PrintErrln("No source found.")
+			return nil
+		}
+
+		for _, source := range sources {
+			fmt.Fprintf(cmd.OutOrStdout(), "ID: %s, Name: %s, Type: %s, Format: %s\n", source.ID, source.Name, source.Type, source.Format)
+		}
+		return nil
+	},
+}
+
+var getSourceCmd = &cobra.Command{
+	Use:   "get-source",
+	Short: "Show a stream source, including its ingestion URL and secret",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		response, err := AppAPI.GetDataSource(sourceID)
+		if err != nil {
+			return err
+		}
+
+		secret := response.Secret
+		source := response.Source
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "ID\t%s\n", source.ID)
+		fmt.Fprintf(w, "Name\t%s\n", source.Name)
+		fmt.Fprintf(w, "Type\t%s\n", source.Type)
+		fmt.Fprintf(w, "Format\t%s\n", source.Format)
+		if source.Compression != "" {
+			fmt.Fprintf(w, "Compression\t%s\n", source.Compression)
+		}
+		if source.S3 != nil {
+			fmt.Fprintf(w, "S3 Bucket\t%s\n", source.S3.Bucket)
+			fmt.Fprintf(w, "S3 Prefix\t%s\n", source.S3.Prefix)
+			fmt.Fprintf(w, "SQS Queue\t%s\n", source.S3.SQSQueue)
+		}
+		if source.HEC != nil {
+			fmt.Fprintf(w, "HEC Index\t%s\n", source.HEC.Index)
+		}
+		if source.Plugin != nil {
+			fmt.Fprintf(w, "Integration ID\t%s\n", source.Plugin.ID)
+		}
+		if response.URL != "" {
+			fmt.Fprintf(w, "URL\t%s\n", response.URL)
+		}
+		if len(secret) > 0 {
+			if showSecret {
+				fmt.Fprintf(w, "Secret\t%s\n", string(secret))
+			} else {
+				fmt.Fprintf(w, "Secret\t%s\n", maskSecret(secret))
+			}
+		}
+		return w.Flush()
+	},
+}
+
+// Example usage:
+// ingext stream rotate-source-secret --id <source-id> --show-secret
+var rotateSourceSecretCmd = &cobra.Command{
+	Use:   "rotate-source-secret",
+	Short: "Generate a new secret for a stream source, invalidating the current one",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.PrintErrf("Rotating secret of source %s...\n", sourceID)
+		secret, err := AppAPI.RotateDataSourceSecret(sourceID)
+		if err != nil {
+			return err
+		}
+		cmd.PrintErrln("Secret rotated. Clients using the previous secret must be updated.")
+		if showSecret {
+			fmt.Fprintln(cmd.OutOrStdout(), string(secret))
+		} else {
+			cmd.PrintErrln("Use --show-secret, or 'get-source --show-secret', to print it.")
+		}
+		return nil
+	},
+}
+
+var updateSourceCmd = &cobra.Command{
+	Use:   "update-source",
+	Short: "Update a stream source",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		response, err := AppAPI.GetDataSource(sourceID)
+		if err != nil {
+			return err
+		}
+		source := response.Source
+
+		// Only overwrite the fields the user explicitly set
+		if cmd.Flags().Changed("name") {
+			source.Name = resourceName
+		}
+		if cmd.Flags().Changed("format") {
+			source.Format = dataFormat
+		}
+		if cmd.Flags().Changed("compression") {
+			source.Compression = normalizeCompression(dataCompression)
+		}
+		if err := validateSourceFormat(source.Type, source.Format, source.Compression); err != nil {
+			return err
+		}
+		if err := applySourceTypeFlags(cmd, source); err != nil {
+			return err
+		}
+		if cmd.Flags().Changed("integration-id") {
+			if source.Type != "plugin" {
+				return fmt.Errorf("integration-id can only be set on plugin sources")
+			}
+			source.Plugin = &model.PluginSourceConfig{
+				ID: integrationID,
+			}
+		}
+
+		cmd.PrintErrf("Updating stream source %s...\n", sourceID)
+		if err := AppAPI.UpdateDataSource(source); err != nil {
+			return err
+		}
+		cmd.PrintErrln("Stream source updated successfully: ", sourceID)
+		return nil
+	},
+}
+
+var delSourceCmd = &cobra.Command{
+	Use:   "del-source",
+	Short: "Delete a stream source",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.PrintErrf("Deleting stream source %s\n", sourceID)
+		err := AppAPI.DeleteDataSource(sourceID)
+		if err != nil {
+			cmd.PrintErrf("Error deleting stream source: %s %v\n", sourceID, err)
+			return err
+		}
+		cmd.PrintErrln("Stream source deleted successfully: ", sourceID)
+		return nil
+	},
+}
+
+// Example usage:
+// 1. ingext stream send --source <id> -f events.ndjson
+// 2. cat events.ndjson | ingext stream send --source <id> --gzip
+var sendCmd = &cobra.Command{
+	Use:   "send",
+	Short: "Send test events into a HEC or webhook source",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var input io.Reader
+		if sendFile == "-" {
+			input = cmd.InOrStdin()
+		} else {
+			f, err := os.Open(sendFile)
+			if err != nil {
+				return fmt.Errorf("failed to read file '%s': %w", sendFile, err)
+			}
+			defer f.Close()
+			input = f
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
+
+		cmd.PrintErrf("Sending events to source %s...\n", sourceID)
+		result, err := AppAPI.SendEvents(ctx, sourceID, input, api.SendOptions{
+			BatchSize: sendBatchSize,
+			Rate:      sendRate,
+			Retries:   sendRetries,
+			Gzip:      sendGzip,
+		})
+		if result != nil {
+			for _, e := range result.Errors {
+				cmd.PrintErrln("Error:", e)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Accepted: %d, Rejected: %d\n", result.Accepted, result.Rejected)
+		}
+		if err != nil {
+			return err
+		}
+		if result.Rejected > 0 {
+			return fmt.Errorf("%d events rejected", result.Rejected)
+		}
+		return nil
+	},
+}
+
+// Example usage:
+// ingext stream tail --source <id> --filter status=500 --filter host~web --limit 20
+var tailCmd = &cobra.Command{
+	Use:   "tail",
+	Short: "Stream sampled events flowing through a source, sink or router as NDJSON",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		kind, id := "source", tailSource
+		if tailSink != "" {
+			kind, id = "sink", tailSink
+		} else if tailRouter != "" {
+			kind, id = "router", tailRouter
+		}
+
+		filters, err := parseEventFilters(tailFilters)
+		if err != nil {
+			return err
+		}
+
+		// Ctrl-C stops the tail cleanly
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
+
+		cmd.PrintErrf("Tailing %s %s (Ctrl-C to stop)...\n", kind, id)
+		count := 0
+		err = AppAPI.TailEvents(ctx, kind, id, tailInterval, func(event json.RawMessage) bool {
+			if !matchEventFilters(event, filters) {
+				return true
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), string(event))
+			count++
+			return tailLimit <= 0 || count < tailLimit
+		})
+		if err != nil {
+			return err
+		}
+		cmd.PrintErrf("%d events received.\n", count)
+		return nil
+	},
+}
+
+// eventFilter is a condition on a (dotted path) event field:
+// field=value, field!=value or field~substring.
+type eventFilter struct {
+	path  []string
+	op    string
+	value string
+}
+
+func parseEventFilters(exprs []string) ([]eventFilter, error) {
+	var filters []eventFilter
+	for _, expr := range exprs {
+		// The first operator in the expression wins, so values may contain '=' or '~'
+		var f eventFilter
+		field, pos := "", -1
+		for _, op := range []string{"!=", "~", "="} {
+			if i := strings.Index(expr, op); i > 0 && (pos < 0 || i < pos) {
+				pos = i
+				field, f.op, f.value = expr[:i], op, expr[i+len(op):]
+			}
+		}
+		if f.op == "" {
+			return nil, fmt.Errorf("invalid filter '%s', expected field=value, field!=value or field~substring", expr)
+		}
+		f.path = strings.Split(field, ".")
+		filters = append(filters, f)
+	}
+	return filters, nil
+}
+
+// matchEventFilters reports whether the event satisfies all filters.
+// Non-object events only match when there are no filters.
+func matchEventFilters(event json.RawMessage, filters []eventFilter) bool {
+	if len(filters) == 0 {
+		return true
+	}
+	var doc interface{}
+	if err := json.Unmarshal(event, &doc); err != nil {
+		return false
+	}
+
+	for _, f := range filters {
+		var v interface{} = doc
+		for _, key := range f.path {
+			m, ok := v.(map[string]interface{})
+			if !ok {
+				v = nil
+				break
+			}
+			v = m[key]
+		}
+
+		// Compare strings as-is and everything else by its JSON text (500, true, ...)
+		actual, found := "", v != nil
+		if str, ok := v.(string); ok {
+			actual = str
+		} else if found {
+			b, _ := json.Marshal(v)
+			actual = string(b)
+		}
+
+		switch f.op {
+		case "=":
+			if !found || actual != f.value {
+				return false
+			}
+		case "!=":
+			if found && actual == f.value {
+				return false
+			}
+		case "~":
+			if !found || !strings.Contains(actual, f.value) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// maskSecret hides secret values while keeping their shape visible:
+// a JSON object keeps its keys, anything else is replaced entirely.
+func maskSecret(raw json.RawMessage) string {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return "********"
+	}
+
+	masked := make(map[string]string, len(fields))
+	for k := range fields {
+		masked[k] = "********"
+	}
+	b, _ := json.Marshal(masked)
+	return string(b)
+}
+
 // Example leaf command: source
 var addSinkCmd = &cobra.Command{
 	Use:   "add-sink",
@@ -83,17 +531,61 @@ var addSinkCmd = &cobra.Command{
 func init() {
 	RootCmd.AddCommand(streamCmd)
 	streamCmd.AddCommand(addSourceCmd, addSinkCmd) // Add del/update similarly
+	streamCmd.AddCommand(listSourceCmd, getSourceCmd, rotateSourceSecretCmd, updateSourceCmd, delSourceCmd, sendCmd, tailCmd)
 
 	addSourceCmd.Flags().StringVar(&sourceType, "source-type", "", "data source type: plugin, s3, hec, webhook ")
 	addSourceCmd.Flags().StringVar(&resourceName, "name", "", "Name")
-	addSourceCmd.Flags().StringVar(&dataFormat, "format", "json", "Data Format")
-	addSourceCmd.Flags().StringVar(&dataCompression, "compression", "", "Data Compression")
+	addSourceCmd.Flags().StringVar(&dataFormat, "format", "json", "Data Format: json, ndjson, csv, syslog, text")
+	addSourceCmd.Flags().StringVar(&dataCompression, "compression", "", "Data Compression: none, gzip, zstd (default none)")
 
 	addSourceCmd.Flags().StringVar(&integrationID, "integration-id", "", "Integration ID")
+	addSourceCmd.Flags().StringVar(&s3Bucket, "s3-bucket", "", "S3 bucket (s3 sources)")
+	addSourceCmd.Flags().StringVar(&s3Prefix, "s3-prefix", "", "S3 key prefix (s3 sources)")
+	addSourceCmd.Flags().StringVar(&s3SQSQueue, "sqs-queue", "", "SQS queue URL for bucket notifications (s3 sources)")
+	addSourceCmd.Flags().StringVar(&hecIndex, "hec-index", "", "Default HEC index (hec sources)")
 
 	_ = addSourceCmd.MarkFlagRequired("source-type")
 	_ = addSourceCmd.MarkFlagRequired("name")
 
+	getSourceCmd.Flags().StringVar(&sourceID, "id", "", "Source ID")
+	getSourceCmd.Flags().BoolVar(&showSecret, "show-secret", false, "Show the source secret instead of masking it")
+	_ = getSourceCmd.MarkFlagRequired("id")
+
+	rotateSourceSecretCmd.Flags().StringVar(&sourceID, "id", "", "Source ID")
+	rotateSourceSecretCmd.Flags().BoolVar(&showSecret, "show-secret", false, "Print the new secret")
+	_ = rotateSourceSecretCmd.MarkFlagRequired("id")
+
+	updateSourceCmd.Flags().StringVar(&sourceID, "id", "", "Source ID")
+	updateSourceCmd.Flags().StringVar(&resourceName, "name", "", "Name")
+	updateSourceCmd.Flags().StringVar(&dataFormat, "format", "json", "Data Format: json, ndjson, csv, syslog, text")
+	updateSourceCmd.Flags().StringVar(&dataCompression, "compression", "", "Data Compression: none, gzip, zstd (default none)")
+	updateSourceCmd.Flags().StringVar(&integrationID, "integration-id", "", "Integration ID (plugin sources only)")
+	updateSourceCmd.Flags().StringVar(&s3Bucket, "s3-bucket", "", "S3 bucket (s3 sources)")
+	updateSourceCmd.Flags().StringVar(&s3Prefix, "s3-prefix", "", "S3 key prefix (s3 sources)")
+	updateSourceCmd.Flags().StringVar(&s3SQSQueue, "sqs-queue", "", "SQS queue URL for bucket notifications (s3 sources)")
+	updateSourceCmd.Flags().StringVar(&hecIndex, "hec-index", "", "Default HEC index (hec sources)")
+	_ = updateSourceCmd.MarkFlagRequired("id")
+
+	delSourceCmd.Flags().StringVar(&sourceID, "id", "", "Source ID")
+	_ = delSourceCmd.MarkFlagRequired("id")
+
+	sendCmd.Flags().StringVar(&sourceID, "source", "", "Source ID")
+	sendCmd.Flags().StringVarP(&sendFile, "file", "f", "-", "NDJSON file with one event per line (use '-' for stdin)")
+	sendCmd.Flags().IntVar(&sendBatchSize, "batch-size", 100, "Events per request")
+	sendCmd.Flags().Float64Var(&sendRate, "rate", 0, "Max events per second (0 for unlimited)")
+	sendCmd.Flags().IntVar(&sendRetries, "retries", 3, "Retries per batch on network errors, 429 and 5xx responses")
+	sendCmd.Flags().BoolVar(&sendGzip, "gzip", false, "Gzip request bodies")
+	_ = sendCmd.MarkFlagRequired("source")
+
+	tailCmd.Flags().StringVar(&tailSource, "source", "", "Source ID")
+	tailCmd.Flags().StringVar(&tailSink, "sink", "", "Sink ID")
+	tailCmd.Flags().StringVar(&tailRouter, "router", "", "Router ID")
+	tailCmd.Flags().IntVar(&tailLimit, "limit", 0, "Stop after this many events (0 for no limit)")
+	tailCmd.Flags().StringArrayVar(&tailFilters, "filter", []string{}, "Only show events matching field=value, field!=value or field~substring (repeatable, dotted paths allowed)")
+	tailCmd.Flags().DurationVar(&tailInterval, "interval", 2*time.Second, "Polling interval")
+	tailCmd.MarkFlagsMutuallyExclusive("source", "sink", "router")
+	tailCmd.MarkFlagsOneRequired("source", "sink", "router")
+
 	//streamAddCmd.AddCommand(streamAddSourceCmd)
 	// Add other leaf commands: sink, router, connection
 }
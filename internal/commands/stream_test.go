@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParseEventFilters(t *testing.T) {
+	tests := []struct {
+		expr    string
+		want    eventFilter
+		wantErr bool
+	}{
+		{expr: "level=error", want: eventFilter{path: []string{"level"}, op: "=", value: "error"}},
+		{expr: "http.status!=200", want: eventFilter{path: []string{"http", "status"}, op: "!=", value: "200"}},
+		{expr: "msg~timeout", want: eventFilter{path: []string{"msg"}, op: "~", value: "timeout"}},
+		{expr: "level=", want: eventFilter{path: []string{"level"}, op: "=", value: ""}},
+		// The first operator wins, the value keeps the others
+		{expr: "query=a=b", want: eventFilter{path: []string{"query"}, op: "=", value: "a=b"}},
+		{expr: "query=a!=b", want: eventFilter{path: []string{"query"}, op: "=", value: "a!=b"}},
+		{expr: "query~x=y", want: eventFilter{path: []string{"query"}, op: "~", value: "x=y"}},
+		{expr: "query!=~x", want: eventFilter{path: []string{"query"}, op: "!=", value: "~x"}},
+		{expr: "level", wantErr: true},
+		{expr: "=error", wantErr: true},
+		{expr: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := parseEventFilters([]string{tt.expr})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseEventFilters() = %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseEventFilters() error = %v", err)
+			}
+			if len(got) != 1 || !reflect.DeepEqual(got[0], tt.want) {
+				t.Errorf("parseEventFilters() = %+v, want [%+v]", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchEventFilters(t *testing.T) {
+	event := json.RawMessage(`{"level":"error","msg":"read timeout","http":{"status":500,"ok":false}}`)
+	tests := []struct {
+		filters []string
+		event   json.RawMessage
+		want    bool
+	}{
+		{filters: nil, event: event, want: true},
+		{filters: nil, event: json.RawMessage(`"text"`), want: true},
+		{filters: []string{"level=error"}, event: event, want: true},
+		{filters: []string{"level=warn"}, event: event, want: false},
+		{filters: []string{"http.status=500"}, event: event, want: true},
+		{filters: []string{"http.ok=false"}, event: event, want: true},
+		{filters: []string{"http.status!=200"}, event: event, want: true},
+		{filters: []string{"missing!=x"}, event: event, want: true},
+		{filters: []string{"missing=x"}, event: event, want: false},
+		{filters: []string{"level.sub=x"}, event: event, want: false},
+		{filters: []string{"msg~timeout"}, event: event, want: true},
+		{filters: []string{"msg~refused"}, event: event, want: false},
+		{filters: []string{"level=error", "msg~refused"}, event: event, want: false},
+		{filters: []string{"level=error"}, event: json.RawMessage(`"level=error"`), want: false},
+	}
+
+	for _, tt := range tests {
+		filters, err := parseEventFilters(tt.filters)
+		if err != nil {
+			t.Fatalf("parseEventFilters(%q) error = %v", tt.filters, err)
+		}
+		if got := matchEventFilters(tt.event, filters); got != tt.want {
+			t.Errorf("matchEventFilters(%s, %q) = %v, want %v", tt.event, tt.filters, got, tt.want)
+		}
+	}
+}
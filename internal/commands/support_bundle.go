@@ -0,0 +1,359 @@
+package commands
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"ingext/internal/api"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	bundleOutput    string
+	bundleLogSince  time.Duration
+	bundleLogLines  int64
+	bundleTimeout   time.Duration
+	bundleInventory bool
+)
+
+// redactedKey matches config keys whose values are secrets
+var redactedKey = regexp.MustCompile(`(?i)(secret|token|password|passwd|credential|private|api_?key|access_?key|auth)`)
+
+// webhookKey matches config keys whose URL values are webhooks, the URL being the credential
+var webhookKey = regexp.MustCompile(`(?i)webhook`)
+
+// webhookHost matches the hosts of Slack and Teams incoming webhooks
+var webhookHost = regexp.MustCompile(`(?i)(^|\.)(hooks\.slack\.com|webhook\.office\.com|outlook\.office\.com)$`)
+
+// redactedParam matches URL query parameters carrying credentials, on top of redactedKey
+var redactedParam = regexp.MustCompile(`(?i)^(sig|signature|code|key)$`)
+
+const redacted = "********"
+
+// redactJSON replaces the values of secret-looking keys, webhook URLs and the
+// credentials inside URLs, at any depth
+func redactJSON(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, val := range t {
+			if redactedKey.MatchString(k) {
+				t[k] = redacted
+			} else if str, ok := val.(string); ok && webhookKey.MatchString(k) && isURL(str) {
+				t[k] = redacted
+			} else {
+				t[k] = redactJSON(val)
+			}
+		}
+	case []interface{}:
+		for i, val := range t {
+			t[i] = redactJSON(val)
+		}
+	case string:
+		return redactURL(t)
+	}
+	return v
+}
+
+func isURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+// redactURL hides the credentials of a URL: the whole URL of a Slack or Teams webhook,
+// the user info and the secret-looking query parameters. Other strings are kept.
+func redactURL(s string) string {
+	if !isURL(s) {
+		return s
+	}
+	u, _ := url.Parse(s)
+	if webhookHost.MatchString(u.Hostname()) {
+		return redacted
+	}
+
+	// The user info is put back by hand, url.User would escape the redacted marker
+	userinfo := ""
+	if u.User != nil {
+		userinfo = redacted + "@"
+		if _, ok := u.User.Password(); ok {
+			userinfo = url.User(u.User.Username()).String() + ":" + userinfo
+		}
+		// A lone user name is often a token (https://<token>@host), it is redacted too
+		u.User = nil
+	}
+	changed := userinfo != ""
+	if u.RawQuery != "" {
+		params := strings.Split(u.RawQuery, "&")
+		for i, param := range params {
+			name, _, _ := strings.Cut(param, "=")
+			if decoded, err := url.QueryUnescape(name); err == nil {
+				name = decoded
+			}
+			if redactedKey.MatchString(name) || redactedParam.MatchString(name) {
+				params[i] = url.QueryEscape(name) + "=" + redacted
+				changed = true
+			}
+		}
+		u.RawQuery = strings.Join(params, "&")
+	}
+	if !changed {
+		return s
+	}
+	return strings.Replace(u.String(), "://", "://"+userinfo, 1)
+}
+
+// redactConfigMap redacts the ConfigMap keys holding secrets and the secret fields
+// of the JSON values. Values that aren't JSON are kept, but for the credentials of URLs.
+func redactConfigMap(data map[string]string) map[string]interface{} {
+	out := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		if redactedKey.MatchString(k) {
+			out[k] = redacted
+			continue
+		}
+		var doc interface{}
+		if err := json.Unmarshal([]byte(v), &doc); err == nil {
+			out[k] = redactJSON(doc)
+		} else {
+			out[k] = redactURL(v)
+		}
+	}
+	return out
+}
+
+// bundleFile is a file of the support bundle
+type bundleFile struct {
+	Name string `json:"name"`
+	Size int    `json:"size"`
+	data []byte
+}
+
+// bundleManifest describes the content of the support bundle
+type bundleManifest struct {
+	CreatedAt  time.Time         `json:"createdAt"`
+	CLIVersion string            `json:"cliVersion"`
+	GoVersion  string            `json:"goVersion"`
+	Platform   string            `json:"platform"`
+	Profile    map[string]string `json:"profile"`
+	Files      []*bundleFile     `json:"files"`
+	// Errors lists what couldn't be collected, the bundle is still written
+	Errors []string `json:"errors,omitempty"`
+}
+
+// supportBundle collects the files of the bundle. Each step is best effort:
+// a failure is recorded in the manifest instead of aborting the collection.
+type supportBundle struct {
+	cmd      *cobra.Command
+	manifest bundleManifest
+}
+
+func (b *supportBundle) add(name string, data []byte) {
+	b.manifest.Files = append(b.manifest.Files, &bundleFile{Name: name, Size: len(data), data: data})
+}
+
+func (b *supportBundle) addJSON(name string, v interface{}) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		b.fail(name, err)
+		return
+	}
+	b.add(name, append(data, '\n'))
+}
+
+func (b *supportBundle) fail(what string, err error) {
+	b.cmd.PrintErrf("Warning: failed to collect %s: %v\n", what, err)
+	b.manifest.Errors = append(b.manifest.Errors, fmt.Sprintf("%s: %v", what, err))
+}
+
+// collectLogs adds the recent logs of every container as logs/<pod>/<container>.log
+func (b *supportBundle) collectLogs(ctx context.Context) {
+	lines := make(chan api.LogLine, 100)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- AppAPI.StreamLogs(ctx, api.LogOptions{Since: bundleLogSince, TailLines: bundleLogLines}, lines)
+	}()
+
+	logs := make(map[string][]byte)
+	for line := range lines {
+		key := fmt.Sprintf("logs/%s/%s.log", line.Pod, line.Container)
+		logs[key] = fmt.Appendf(logs[key], "%s %s\n", line.Time.Format(time.RFC3339Nano), line.Text)
+	}
+	if err := <-errc; err != nil {
+		b.fail("logs", err)
+	}
+	for _, name := range sortedKeys(logs) {
+		b.add(name, logs[name])
+	}
+}
+
+// addRedactedJSON adds v with the values of its secret-looking keys redacted, see redactJSON
+func (b *supportBundle) addRedactedJSON(name string, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		b.fail(name, err)
+		return
+	}
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		b.fail(name, err)
+		return
+	}
+	b.addJSON(name, redactJSON(doc))
+}
+
+// collectInventory adds the platform resources, with their secrets redacted
+func (b *supportBundle) collectInventory() {
+	if err := AppAPI.Login(); err != nil {
+		b.fail("platform inventory", err)
+		return
+	}
+
+	if sources, err := AppAPI.ListDataSource(); err != nil {
+		b.fail("sources", err)
+	} else {
+		b.addRedactedJSON("inventory/sources.json", sources)
+	}
+	if sinks, err := AppAPI.ListDataSink(); err != nil {
+		b.fail("sinks", err)
+	} else {
+		b.addRedactedJSON("inventory/sinks.json", sinks)
+	}
+	if routers, err := AppAPI.ListRouter(); err != nil {
+		b.fail("routers", err)
+	} else {
+		b.addRedactedJSON("inventory/routers.json", routers)
+	}
+	// The integration secrets are redacted as a whole by their key
+	if integrations, err := AppAPI.ListIntegration(); err != nil {
+		b.fail("integrations", err)
+	} else {
+		b.addRedactedJSON("inventory/integrations.json", integrations)
+	}
+}
+
+// write creates the tar.gz with the manifest first, all files under a top directory
+func (b *supportBundle) write(path, dir string) (err error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create support bundle: %w", err)
+	}
+	defer func() {
+		f.Close()
+		// Don't leave a truncated bundle behind
+		if err != nil {
+			os.Remove(path)
+		}
+	}()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	manifest, err := json.MarshalIndent(b.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	files := append([]*bundleFile{{Name: "manifest.json", data: append(manifest, '\n')}}, b.manifest.Files...)
+	for _, file := range files {
+		hdr := &tar.Header{Name: dir + "/" + file.Name, Mode: 0600, Size: int64(len(file.data)), ModTime: b.manifest.CreatedAt}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return fmt.Errorf("failed to write support bundle: %w", err)
+		}
+		if _, err := tw.Write(file.data); err != nil {
+			return fmt.Errorf("failed to write support bundle: %w", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to write support bundle: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to write support bundle: %w", err)
+	}
+	return f.Close()
+}
+
+// Example usage:
+// ingext support-bundle --log-since 2h -o /tmp/ingext-support.tar.gz
+var supportBundleCmd = &cobra.Command{
+	Use:         "support-bundle",
+	Short:       "Collect diagnostics into a tar.gz to attach to a support ticket",
+	Long:        "Collects pod status, events, recent logs, the redacted site config, the platform inventory (sources, sinks, routers and integrations with secrets redacted), the CLI version and the profile into a timestamped tar.gz with a manifest.",
+	Annotations: map[string]string{clusterCommand: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		now := time.Now().UTC()
+		dir := fmt.Sprintf("ingext-support-%s-%s", AppAPI.Cluster, now.Format("20060102-150405"))
+		path := bundleOutput
+		if path == "" {
+			path = dir + ".tar.gz"
+		}
+
+		b := &supportBundle{cmd: cmd, manifest: bundleManifest{
+			CreatedAt:  now,
+			CLIVersion: Version,
+			GoVersion:  runtime.Version(),
+			Platform:   runtime.GOOS + "/" + runtime.GOARCH,
+			Profile: map[string]string{
+				"cluster":   viper.GetString("cluster"),
+				"provider":  viper.GetString("provider"),
+				"namespace": viper.GetString("namespace"),
+				"context":   viper.GetString("context"),
+			},
+		}}
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), bundleTimeout)
+		defer cancel()
+
+		cmd.PrintErrln("Collecting pod status and events...")
+		if pods, err := AppAPI.ListPodInfo(ctx); err != nil {
+			b.fail("pods", err)
+		} else {
+			b.addJSON("pods.json", pods)
+		}
+		if events, err := AppAPI.ListEvents(ctx); err != nil {
+			b.fail("events", err)
+		} else {
+			sort.Slice(events, func(i, j int) bool { return events[i].Time.Before(events[j].Time) })
+			b.addJSON("events.json", events)
+		}
+		if config, err := AppAPI.GetSiteConfigMap(ctx); err != nil {
+			b.fail("site config", err)
+		} else {
+			b.addJSON("site-config.json", redactConfigMap(config))
+		}
+
+		cmd.PrintErrln("Collecting logs...")
+		b.collectLogs(ctx)
+
+		if bundleInventory {
+			cmd.PrintErrln("Collecting platform inventory...")
+			b.collectInventory()
+		}
+
+		if err := b.write(path, dir); err != nil {
+			return err
+		}
+		cmd.PrintErrf("Support bundle written with %d files and %d errors\n", len(b.manifest.Files)+1, len(b.manifest.Errors))
+		fmt.Fprintln(cmd.OutOrStdout(), path)
+		return nil
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(supportBundleCmd)
+
+	supportBundleCmd.Flags().StringVarP(&bundleOutput, "output", "o", "", "Output file (default: ingext-support-<cluster>-<timestamp>.tar.gz)")
+	supportBundleCmd.Flags().DurationVar(&bundleLogSince, "log-since", time.Hour, "Collect the logs of this last duration")
+	supportBundleCmd.Flags().Int64Var(&bundleLogLines, "log-lines", 5000, "Maximum number of log lines per container (0 for all)")
+	supportBundleCmd.Flags().DurationVar(&bundleTimeout, "timeout", 2*time.Minute, "Maximum time spent collecting from the cluster")
+	supportBundleCmd.Flags().BoolVar(&bundleInventory, "inventory", true, "Include the platform inventory (needs the platform to be reachable)")
+}
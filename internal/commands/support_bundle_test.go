@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRedactJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want string
+	}{
+		{name: "secret keys", doc: `{"name":"n","apiKey":"k","nested":{"password":"p","list":[{"token":"t","port":1}]}}`,
+			want: `{"apiKey":"********","name":"n","nested":{"list":[{"port":1,"token":"********"}],"password":"********"}}`},
+		{name: "secret key holding an object", doc: `{"credentials":{"user":"u"}}`, want: `{"credentials":"********"}`},
+		{name: "slack url", doc: `{"url":"https://hooks.slack.com/services/T0/B0/xyz"}`, want: `{"url":"********"}`},
+		{name: "teams url", doc: `{"url":"https://contoso.webhook.office.com/webhookb2/abc"}`, want: `{"url":"********"}`},
+		{name: "webhook key", doc: `{"webhook":"https://example.com/hook/abc","webhookName":"alerts"}`, want: `{"webhook":"********","webhookName":"alerts"}`},
+		{name: "user and password", doc: `{"dsn":"postgres://admin:s3cr3t@db:5432/ingext"}`, want: `{"dsn":"postgres://admin:********@db:5432/ingext"}`},
+		{name: "user only", doc: `{"repo":"https://ghp_abc@github.com/o/r"}`, want: `{"repo":"https://********@github.com/o/r"}`},
+		{name: "query token", doc: `{"endpoint":"https://x.example.com/in?token=abc&region=us"}`, want: `{"endpoint":"https://x.example.com/in?token=********&region=us"}`},
+		{name: "query signature", doc: `["https://x.logic.azure.com/workflows/1?api-version=1&sig=abc"]`, want: `["https://x.logic.azure.com/workflows/1?api-version=1&sig=********"]`},
+		{name: "plain urls and strings", doc: `{"url":"https://example.com/in?region=us","host":"hooks.slack.com","note":"user:pass"}`,
+			want: `{"host":"hooks.slack.com","note":"user:pass","url":"https://example.com/in?region=us"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var doc interface{}
+			if err := json.Unmarshal([]byte(tt.doc), &doc); err != nil {
+				t.Fatal(err)
+			}
+			var got strings.Builder
+			enc := json.NewEncoder(&got)
+			enc.SetEscapeHTML(false)
+			if err := enc.Encode(redactJSON(doc)); err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.TrimSpace(got.String()); got != tt.want {
+				t.Errorf("redactJSON() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactConfigMap(t *testing.T) {
+	data := map[string]string{
+		"SITE_TOKEN": "t",
+		"settings":   `{"password":"p","region":"us"}`,
+		"ALERT_URL":  "https://hooks.slack.com/services/T0/B0/xyz",
+		"SITE_NAME":  "prod",
+	}
+	want := map[string]interface{}{
+		"SITE_TOKEN": redacted,
+		"settings":   map[string]interface{}{"password": redacted, "region": "us"},
+		"ALERT_URL":  redacted,
+		"SITE_NAME":  "prod",
+	}
+	if got := redactConfigMap(data); !reflect.DeepEqual(got, want) {
+		t.Errorf("redactConfigMap() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,137 @@
+package processor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// ManifestFiles are the manifest names looked up in a processor directory, in order
+var ManifestFiles = []string{"manifest.yaml", "manifest.yml", "manifest.json"}
+
+// includeDirective matches `// @include "lib/helpers.js"`, which is still valid JavaScript
+var includeDirective = regexp.MustCompile(`^\s*//\s*@include\s+"([^"]+)"\s*$`)
+
+// Manifest describes a multi-file processor directory
+type Manifest struct {
+	Name        string `json:"name"`
+	Entry       string `json:"entry"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version,omitempty"`
+}
+
+// Bundle is a processor directory resolved into a single deployable script.
+// The first line of Content carries the manifest as a `// @manifest {...}` comment.
+type Bundle struct {
+	Manifest Manifest
+	Content  []byte
+
+	origins []lineOrigin // where each line of Content comes from
+}
+
+type lineOrigin struct {
+	file string
+	line int
+}
+
+// Origin maps a 1-based line of the bundle back to its source file (relative to the
+// processor directory) and line
+func (b *Bundle) Origin(line int) (file string, fileLine int) {
+	if line < 1 || line > len(b.origins) {
+		return "", 0
+	}
+	o := b.origins[line-1]
+	return o.file, o.line
+}
+
+// BundleDir reads the manifest of dir and inlines every `// @include` of the entry
+// file, recursively. Each file is included once; includes must stay inside dir.
+func BundleDir(dir string) (*Bundle, error) {
+	b := &Bundle{}
+
+	manifestPath := ""
+	for _, name := range ManifestFiles {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			manifestPath = filepath.Join(dir, name)
+			break
+		}
+	}
+	if manifestPath == "" {
+		return nil, fmt.Errorf("no manifest found in '%s', expected one of: %s", dir, strings.Join(ManifestFiles, ", "))
+	}
+	raw, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	if err := yaml.UnmarshalStrict(raw, &b.Manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest '%s': %w", manifestPath, err)
+	}
+	if b.Manifest.Entry == "" {
+		return nil, fmt.Errorf("manifest '%s' has no entry file", manifestPath)
+	}
+
+	header, _ := json.Marshal(b.Manifest)
+	lines := []string{"// @manifest " + string(header)}
+	b.origins = []lineOrigin{{file: filepath.Base(manifestPath), line: 1}}
+
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	r := &bundler{root: root, seen: make(map[string]bool)}
+	if err := r.include(filepath.Join(root, b.Manifest.Entry), nil); err != nil {
+		return nil, err
+	}
+
+	b.Content = []byte(strings.Join(append(lines, r.lines...), "\n") + "\n")
+	b.origins = append(b.origins, r.origins...)
+	return b, nil
+}
+
+type bundler struct {
+	root    string
+	seen    map[string]bool
+	lines   []string
+	origins []lineOrigin
+}
+
+// include appends the file with its includes resolved in place. stack holds the
+// files currently being included, to report cycles.
+func (r *bundler) include(path string, stack []string) error {
+	rel, err := filepath.Rel(r.root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("include '%s' is outside the processor directory", path)
+	}
+	for _, s := range stack {
+		if s == rel {
+			return fmt.Errorf("include cycle: %s -> %s", strings.Join(stack, " -> "), rel)
+		}
+	}
+	if r.seen[rel] {
+		return nil
+	}
+	r.seen[rel] = true
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read file '%s': %w", rel, err)
+	}
+
+	for i, line := range strings.Split(strings.TrimSuffix(string(content), "\n"), "\n") {
+		if m := includeDirective.FindStringSubmatch(line); m != nil {
+			target := filepath.Join(filepath.Dir(path), m[1])
+			if err := r.include(target, append(stack, rel)); err != nil {
+				return fmt.Errorf("%s:%d: %w", rel, i+1, err)
+			}
+			continue
+		}
+		r.lines = append(r.lines, line)
+		r.origins = append(r.origins, lineOrigin{file: rel, line: i + 1})
+	}
+	return nil
+}
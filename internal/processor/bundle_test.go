@@ -0,0 +1,143 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFiles creates the files, given by their slash separated path, under a temp dir
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestBundleDir(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		want    string // Content without the manifest line
+		wantErr string
+	}{
+		{
+			name: "includes are inlined once",
+			files: map[string]string{
+				"manifest.yaml": "name: p\nentry: main.js\n",
+				"main.js":       "// @include \"lib/a.js\"\n// @include \"lib/b.js\"\nfunction process(e) {}\n",
+				"lib/a.js":      "// @include \"common.js\"\nvar a = 1;\n",
+				"lib/b.js":      "// @include \"common.js\"\nvar b = 2;\n",
+				"lib/common.js": "var common = 0;\n",
+				"lib/unused.js": "var unused;\n",
+				"manifest.json": "ignored, manifest.yaml is looked up first",
+			},
+			want: "var common = 0;\nvar a = 1;\nvar b = 2;\nfunction process(e) {}\n",
+		},
+		{
+			name: "JSON manifest",
+			files: map[string]string{
+				"manifest.json": `{"name":"p","entry":"main.js"}`,
+				"main.js":       "function process(e) {}",
+			},
+			want: "function process(e) {}\n",
+		},
+		{
+			name:    "no manifest",
+			files:   map[string]string{"main.js": ""},
+			wantErr: "no manifest found",
+		},
+		{
+			name:    "unknown manifest field",
+			files:   map[string]string{"manifest.yaml": "name: p\nentry: main.js\nmain: x\n", "main.js": ""},
+			wantErr: "invalid manifest",
+		},
+		{
+			name:    "no entry",
+			files:   map[string]string{"manifest.yaml": "name: p\n"},
+			wantErr: "has no entry file",
+		},
+		{
+			name:    "missing include",
+			files:   map[string]string{"manifest.yaml": "entry: main.js\n", "main.js": "var x;\n// @include \"lib.js\"\n"},
+			wantErr: "main.js:2: failed to read file 'lib.js'",
+		},
+		{
+			name:    "include outside the directory",
+			files:   map[string]string{"manifest.yaml": "entry: main.js\n", "main.js": "// @include \"../secret.js\"\n"},
+			wantErr: "is outside the processor directory",
+		},
+		{
+			name: "include cycle",
+			files: map[string]string{
+				"manifest.yaml": "entry: main.js\n",
+				"main.js":       "// @include \"a.js\"\n",
+				"a.js":          "// @include \"b.js\"\n",
+				"b.js":          "// @include \"a.js\"\n",
+			},
+			wantErr: "include cycle: main.js -> a.js -> b.js -> a.js",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := BundleDir(writeFiles(t, tt.files))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("BundleDir() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BundleDir() error = %v", err)
+			}
+			manifest, content, _ := strings.Cut(string(b.Content), "\n")
+			if !strings.HasPrefix(manifest, "// @manifest {") {
+				t.Errorf("BundleDir() first line = %q, want the manifest", manifest)
+			}
+			if content != tt.want {
+				t.Errorf("BundleDir() content = %q, want %q", content, tt.want)
+			}
+		})
+	}
+}
+
+func TestBundleOrigin(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"manifest.yaml": "name: p\nentry: main.js\n",
+		"main.js":       "var x;\n// @include \"lib/a.js\"\nfunction process(e) {}\n",
+		"lib/a.js":      "var a;\nvar b;\n",
+	})
+	b, err := BundleDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		line     int
+		file     string
+		fileLine int
+	}{
+		{line: 1, file: "manifest.yaml", fileLine: 1},
+		{line: 2, file: "main.js", fileLine: 1},
+		{line: 3, file: filepath.Join("lib", "a.js"), fileLine: 1},
+		{line: 4, file: filepath.Join("lib", "a.js"), fileLine: 2},
+		{line: 5, file: "main.js", fileLine: 3},
+		{line: 6, file: "", fileLine: 0},
+		{line: 0, file: "", fileLine: 0},
+	}
+	for _, tt := range tests {
+		if file, fileLine := b.Origin(tt.line); file != tt.file || fileLine != tt.fileLine {
+			t.Errorf("Origin(%d) = %s:%d, want %s:%d", tt.line, file, fileLine, tt.file, tt.fileLine)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package processor
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxDiffCells bounds the LCS table; larger changes fall back to a full replace
+const maxDiffCells = 4 * 1024 * 1024
+
+// diffContext is the number of unchanged lines shown around each change
+const diffContext = 3
+
+// noNewline marks a last line without a trailing newline, so it differs from
+// the same line with one
+const noNewline = "\x00"
+
+type diffLine struct {
+	op   byte // ' ', '-' or '+'
+	text string
+}
+
+// Diff returns a unified diff turning a into b, or "" when they are identical
+func Diff(aName, bName string, a, b []byte) string {
+	if string(a) == string(b) {
+		return ""
+	}
+	lines := diffLines(splitLines(string(a)), splitLines(string(b)))
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", aName, bName)
+
+	// Group the changes into hunks with diffContext lines around them
+	for i := 0; i < len(lines); {
+		if lines[i].op == ' ' {
+			i++
+			continue
+		}
+		start := max(i-diffContext, 0)
+		end := i
+		for end < len(lines) {
+			if lines[end].op != ' ' {
+				end++
+				continue
+			}
+			// Look ahead: merge with the next change if the gap is small
+			next := end
+			for next < len(lines) && lines[next].op == ' ' {
+				next++
+			}
+			if next < len(lines) && next-end <= 2*diffContext {
+				end = next
+				continue
+			}
+			end = min(end+diffContext, len(lines))
+			break
+		}
+		writeHunk(&sb, lines, start, end)
+		i = end
+	}
+	return sb.String()
+}
+
+func writeHunk(sb *strings.Builder, lines []diffLine, start, end int) {
+	// Line numbers are 1-based positions in a and b at the start of the hunk
+	aLine, bLine := 1, 1
+	for _, l := range lines[:start] {
+		if l.op != '+' {
+			aLine++
+		}
+		if l.op != '-' {
+			bLine++
+		}
+	}
+	aCount, bCount := 0, 0
+	for _, l := range lines[start:end] {
+		if l.op != '+' {
+			aCount++
+		}
+		if l.op != '-' {
+			bCount++
+		}
+	}
+
+	// An empty side is numbered after the line preceding the hunk, e.g. -0,0 at the start
+	if aCount == 0 {
+		aLine--
+	}
+	if bCount == 0 {
+		bLine--
+	}
+	fmt.Fprintf(sb, "@@ -%d,%d +%d,%d @@\n", aLine, aCount, bLine, bCount)
+	for _, l := range lines[start:end] {
+		sb.WriteByte(l.op)
+		if text, ok := strings.CutSuffix(l.text, noNewline); ok {
+			sb.WriteString(text)
+			sb.WriteString("\n\\ No newline at end of file\n")
+			continue
+		}
+		sb.WriteString(l.text)
+		sb.WriteByte('\n')
+	}
+}
+
+// diffLines computes a line diff with a longest common subsequence table,
+// after trimming the common prefix and suffix.
+func diffLines(a, b []string) []diffLine {
+	var prefix, suffix []diffLine
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		prefix = append(prefix, diffLine{' ', a[0]})
+		a, b = a[1:], b[1:]
+	}
+	for len(a) > 0 && len(b) > 0 && a[len(a)-1] == b[len(b)-1] {
+		suffix = append([]diffLine{{' ', a[len(a)-1]}}, suffix...)
+		a, b = a[:len(a)-1], b[:len(b)-1]
+	}
+
+	var middle []diffLine
+	if (len(a)+1)*(len(b)+1) > maxDiffCells {
+		for _, l := range a {
+			middle = append(middle, diffLine{'-', l})
+		}
+		for _, l := range b {
+			middle = append(middle, diffLine{'+', l})
+		}
+	} else {
+		// lcs[i][j] is the LCS length of a[i:] and b[j:]
+		lcs := make([][]int32, len(a)+1)
+		for i := range lcs {
+			lcs[i] = make([]int32, len(b)+1)
+		}
+		for i := len(a) - 1; i >= 0; i-- {
+			for j := len(b) - 1; j >= 0; j-- {
+				if a[i] == b[j] {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else {
+					lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+				}
+			}
+		}
+
+		i, j := 0, 0
+		for i < len(a) || j < len(b) {
+			switch {
+			case i < len(a) && j < len(b) && a[i] == b[j]:
+				middle = append(middle, diffLine{' ', a[i]})
+				i++
+				j++
+			case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+				middle = append(middle, diffLine{'-', a[i]})
+				i++
+			default:
+				middle = append(middle, diffLine{'+', b[j]})
+				j++
+			}
+		}
+	}
+
+	return append(append(prefix, middle...), suffix...)
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	text, ok := strings.CutSuffix(s, "\n")
+	lines := strings.Split(text, "\n")
+	if !ok {
+		lines[len(lines)-1] += noNewline
+	}
+	return lines
+}
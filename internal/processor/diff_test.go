@@ -0,0 +1,102 @@
+package processor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{name: "identical", a: "a\nb\n", b: "a\nb\n", want: ""},
+		{name: "both empty", a: "", b: "", want: ""},
+		{
+			name: "changed line",
+			a:    "a\nb\nc\n",
+			b:    "a\nB\nc\n",
+			want: "@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			name: "added to empty",
+			a:    "",
+			b:    "a\n",
+			want: "@@ -0,0 +1,1 @@\n+a\n",
+		},
+		{
+			name: "removed to empty",
+			a:    "a\nb\n",
+			b:    "",
+			want: "@@ -1,2 +0,0 @@\n-a\n-b\n",
+		},
+		{
+			name: "removed line",
+			a:    "a\nb\n",
+			b:    "a\n",
+			want: "@@ -1,2 +1,1 @@\n a\n-b\n",
+		},
+		{
+			name: "trailing newline added",
+			a:    "a\nb",
+			b:    "a\nb\n",
+			want: "@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+		},
+		{
+			name: "trailing newline removed",
+			a:    "a\n",
+			b:    "a",
+			want: "@@ -1,1 +1,1 @@\n-a\n+a\n\\ No newline at end of file\n",
+		},
+		{
+			name: "context is limited to three lines",
+			a:    "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			b:    "1\n2\n3\n4\nV\n6\n7\n8\n9\n",
+			want: "@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+V\n 6\n 7\n 8\n",
+		},
+		{
+			name: "close changes share a hunk",
+			a:    "1\n2\n3\n4\n5\n6\n7\n8\n",
+			b:    "X\n2\n3\n4\n5\n6\n7\nY\n",
+			want: "@@ -1,8 +1,8 @@\n-1\n+X\n 2\n 3\n 4\n 5\n 6\n 7\n-8\n+Y\n",
+		},
+		{
+			name: "distant changes get their own hunks",
+			a:    "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			b:    "X\n2\n3\n4\n5\n6\n7\n8\n9\nY\n",
+			want: "@@ -1,4 +1,4 @@\n-1\n+X\n 2\n 3\n 4\n@@ -7,4 +7,4 @@\n 7\n 8\n 9\n-10\n+Y\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Diff("deployed", "local", []byte(tt.a), []byte(tt.b))
+			if tt.want != "" {
+				tt.want = "--- deployed\n+++ local\n" + tt.want
+			}
+			if got != tt.want {
+				t.Errorf("Diff() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffLargeChange(t *testing.T) {
+	// Beyond maxDiffCells the changed block is replaced as a whole
+	var a, b strings.Builder
+	for i := 0; i < 2100; i++ {
+		a.WriteString("a\n")
+		b.WriteString("b\n")
+	}
+	got := Diff("deployed", "local", []byte(a.String()), []byte(b.String()))
+	if !strings.HasPrefix(got, "--- deployed\n+++ local\n@@ -1,2100 +1,2100 @@\n") {
+		t.Fatalf("Diff() header = %q", got[:min(len(got), 60)])
+	}
+	if n := strings.Count(got, "\n-a"); n != 2100 {
+		t.Errorf("Diff() removed %d lines, want 2100", n)
+	}
+	if n := strings.Count(got, "\n+b"); n != 2100 {
+		t.Errorf("Diff() added %d lines, want 2100", n)
+	}
+}
@@ -0,0 +1,211 @@
+package processor
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dop251/goja/ast"
+	"github.com/dop251/goja/file"
+	"github.com/dop251/goja/parser"
+	"github.com/dop251/goja/token"
+)
+
+// MaxScriptSize is the largest processor the platform accepts
+const MaxScriptSize = 512 * 1024
+
+// Issue is a problem found in a processor script. Line and Column are 1-based, 0 when unknown.
+type Issue struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (i Issue) String() string {
+	if i.Line == 0 {
+		return i.Message
+	}
+	return fmt.Sprintf("%d:%d: %s", i.Line, i.Column, i.Message)
+}
+
+// Lint checks a processor script before it's uploaded: size limit,
+// syntax, and the 'function process(event)' entry point.
+func Lint(name string, script []byte) []Issue {
+	var issues []Issue
+	if len(script) == 0 {
+		return []Issue{{Message: "processor content is empty"}}
+	}
+	if len(script) > MaxScriptSize {
+		issues = append(issues, Issue{Message: fmt.Sprintf("processor is %d bytes, the limit is %d bytes", len(script), MaxScriptSize)})
+	}
+
+	program, err := parser.ParseFile(nil, name, script, 0)
+	if err != nil {
+		var list parser.ErrorList
+		if errors.As(err, &list) {
+			for _, e := range list {
+				issue := Issue{Line: e.Position.Line, Column: e.Position.Column, Message: e.Message}
+				// The parser may report the same error several times while recovering
+				if len(issues) > 0 && issues[len(issues)-1] == issue {
+					continue
+				}
+				issues = append(issues, issue)
+			}
+		} else {
+			issues = append(issues, Issue{Message: err.Error()})
+		}
+		return issues
+	}
+
+	if issue := checkEntry(program); issue != nil {
+		issues = append(issues, *issue)
+	}
+	return issues
+}
+
+// entryFunction is a definition of the entry function found in a script
+type entryFunction struct {
+	idx       file.Idx
+	params    *ast.ParameterList
+	async     bool
+	generator bool
+}
+
+func entryFromExpression(expr ast.Expression) *entryFunction {
+	switch f := expr.(type) {
+	case *ast.FunctionLiteral:
+		return &entryFunction{idx: f.Idx0(), params: f.ParameterList, async: f.Async, generator: f.Generator}
+	case *ast.ArrowFunctionLiteral:
+		return &entryFunction{idx: f.Idx0(), params: f.ParameterList, async: f.Async}
+	}
+	return nil
+}
+
+func isEntryName(target interface{}) bool {
+	id, ok := target.(*ast.Identifier)
+	return ok && id.Name == EntryFunction
+}
+
+// entryOf returns the definition of the entry function made by a statement, if any:
+// a function declaration, a var, let or const initialized with a function, or an assignment.
+func entryOf(stmt ast.Statement) *entryFunction {
+	var entry *entryFunction
+	switch s := stmt.(type) {
+	case *ast.FunctionDeclaration:
+		if s.Function.Name != nil && s.Function.Name.Name == EntryFunction {
+			entry = entryFromExpression(s.Function)
+		}
+	case *ast.VariableStatement:
+		for _, b := range s.List {
+			if isEntryName(b.Target) && b.Initializer != nil {
+				entry = entryFromExpression(b.Initializer)
+			}
+		}
+	case *ast.LexicalDeclaration:
+		for _, b := range s.List {
+			if isEntryName(b.Target) && b.Initializer != nil {
+				entry = entryFromExpression(b.Initializer)
+			}
+		}
+	case *ast.ExpressionStatement:
+		if a, ok := s.Expression.(*ast.AssignExpression); ok && a.Operator == token.ASSIGN && isEntryName(a.Left) {
+			entry = entryFromExpression(a.Right)
+		}
+	}
+	return entry
+}
+
+// findEntry returns the last top-level definition of the entry function
+func findEntry(program *ast.Program) *entryFunction {
+	var entry *entryFunction
+	for _, stmt := range program.Body {
+		if e := entryOf(stmt); e != nil {
+			entry = e
+		}
+	}
+	return entry
+}
+
+// nestedStatements returns the statements directly nested in a block, branch, loop,
+// label, switch or try statement. Function bodies are not included.
+func nestedStatements(stmt ast.Statement) []ast.Statement {
+	switch s := stmt.(type) {
+	case *ast.BlockStatement:
+		return s.List
+	case *ast.IfStatement:
+		if s.Alternate != nil {
+			return []ast.Statement{s.Consequent, s.Alternate}
+		}
+		return []ast.Statement{s.Consequent}
+	case *ast.ForStatement:
+		return []ast.Statement{s.Body}
+	case *ast.ForInStatement:
+		return []ast.Statement{s.Body}
+	case *ast.ForOfStatement:
+		return []ast.Statement{s.Body}
+	case *ast.WhileStatement:
+		return []ast.Statement{s.Body}
+	case *ast.DoWhileStatement:
+		return []ast.Statement{s.Body}
+	case *ast.WithStatement:
+		return []ast.Statement{s.Body}
+	case *ast.LabelledStatement:
+		return []ast.Statement{s.Statement}
+	case *ast.SwitchStatement:
+		var list []ast.Statement
+		for _, c := range s.Body {
+			list = append(list, c.Consequent...)
+		}
+		return list
+	case *ast.TryStatement:
+		list := []ast.Statement{s.Body}
+		if s.Catch != nil {
+			list = append(list, s.Catch.Body)
+		}
+		if s.Finally != nil {
+			list = append(list, s.Finally)
+		}
+		return list
+	}
+	return nil
+}
+
+// findNestedEntry returns the first definition of the entry function inside
+// the blocks of the statements, at any depth
+func findNestedEntry(stmts []ast.Statement) *entryFunction {
+	for _, stmt := range stmts {
+		for _, nested := range nestedStatements(stmt) {
+			if e := entryOf(nested); e != nil {
+				return e
+			}
+			if e := findNestedEntry([]ast.Statement{nested}); e != nil {
+				return e
+			}
+		}
+	}
+	return nil
+}
+
+// checkEntry validates the entry function. It is shared by Lint and NewRunner,
+// so 'processor test' accepts exactly the scripts 'processor add' uploads.
+// Only top-level definitions count; one made only inside a block is an error.
+func checkEntry(program *ast.Program) *Issue {
+	entry := findEntry(program)
+	if entry == nil {
+		// A definition in a block (e.g. inside an if) is only made when the block runs
+		if nested := findNestedEntry(program.Body); nested != nil {
+			pos := program.File.Position(int(nested.idx) - program.File.Base())
+			return &Issue{Line: pos.Line, Column: pos.Column, Message: fmt.Sprintf("'%s' is defined inside a block, define it at the top level of the script", EntryFunction)}
+		}
+		return &Issue{Message: fmt.Sprintf("missing entry function, the processor must define 'function %s(event)'", EntryFunction)}
+	}
+
+	pos := program.File.Position(int(entry.idx) - program.File.Base())
+	params := entry.params
+	switch {
+	case entry.async || entry.generator:
+		return &Issue{Line: pos.Line, Column: pos.Column, Message: fmt.Sprintf("'%s' can't be async or a generator", EntryFunction)}
+	case len(params.List) != 1 || params.Rest != nil:
+		return &Issue{Line: pos.Line, Column: pos.Column, Message: fmt.Sprintf("'%s' must take exactly one parameter (the event), found %d", EntryFunction, len(params.List))}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package processor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLintEntry(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   string // substring of the single issue, "" for none
+	}{
+		{name: "function declaration", script: "function process(event) { return event; }"},
+		{name: "var function", script: "var process = function (event) { return event; };"},
+		{name: "const arrow", script: "const process = (event) => event;"},
+		{name: "assignment", script: "var process;\nprocess = function (event) { return event; };"},
+		{name: "last definition wins", script: "function process() {}\nprocess = function (event) {};"},
+		{name: "missing", script: "function transform(event) {}", want: "missing entry function"},
+		{name: "no parameter", script: "function process() {}", want: "1:1: 'process' must take exactly one parameter (the event), found 0"},
+		{name: "two parameters", script: "const process = (a, b) => a;", want: "must take exactly one parameter"},
+		{name: "rest parameter", script: "function process(...events) {}", want: "must take exactly one parameter"},
+		{name: "async", script: "async function process(event) {}", want: "can't be async or a generator"},
+		{name: "generator", script: "function* process(event) {}", want: "can't be async or a generator"},
+		{name: "not a function", script: "var process = 42;", want: "missing entry function"},
+		{name: "inside an if", script: "if (true) {\n  function process(event) {}\n}", want: "2:3: 'process' is defined inside a block"},
+		{name: "inside a try", script: "try {\n  var process = function (event) {};\n} catch (e) {}", want: "is defined inside a block"},
+		{name: "inside a switch", script: "switch (1) {\ncase 1:\n  process = (event) => event;\n}", want: "is defined inside a block"},
+		{name: "inside a function is local", script: "function setup() {\n  function process(event) {}\n}", want: "missing entry function"},
+		{name: "top level wins over a block", script: "function process(event) {}\nif (false) { process = null; }"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := Lint("p.js", []byte(tt.script))
+			if tt.want == "" {
+				if len(issues) != 0 {
+					t.Fatalf("Lint() = %v, want no issue", issues)
+				}
+				return
+			}
+			if len(issues) != 1 || !strings.Contains(issues[0].String(), tt.want) {
+				t.Fatalf("Lint() = %v, want an issue with %q", issues, tt.want)
+			}
+		})
+	}
+}
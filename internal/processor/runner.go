@@ -0,0 +1,142 @@
+package processor
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/dop251/goja/parser"
+)
+
+// EntryFunction is the function every processor script must define.
+// It receives one event and returns:
+//   - an object: the transformed event
+//   - an array of objects: zero or more events
+//   - null, undefined or false: the event is dropped
+//
+// Any other value is an error.
+const EntryFunction = "process"
+
+// Runner executes a processor script locally, the same content `processor add` uploads
+type Runner struct {
+	vm      *goja.Runtime
+	fn      goja.Callable
+	timeout time.Duration
+}
+
+// NewRunner compiles the script and resolves its entry function.
+// console.log output of the script goes to logWriter.
+func NewRunner(name string, script []byte, logWriter io.Writer, timeout time.Duration) (*Runner, error) {
+	program, err := goja.Compile(name, string(script), false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile processor: %w", err)
+	}
+	parsed, err := parser.ParseFile(nil, name, script, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile processor: %w", err)
+	}
+	if issue := checkEntry(parsed); issue != nil {
+		return nil, fmt.Errorf("invalid processor: %s", issue)
+	}
+
+	vm := goja.New()
+	console := vm.NewObject()
+	_ = console.Set("log", func(call goja.FunctionCall) goja.Value {
+		args := make([]interface{}, 0, len(call.Arguments))
+		for _, a := range call.Arguments {
+			args = append(args, a.Export())
+		}
+		fmt.Fprintln(logWriter, args...)
+		return goja.Undefined()
+	})
+	_ = vm.Set("console", console)
+
+	if _, err := vm.RunProgram(program); err != nil {
+		return nil, fmt.Errorf("failed to load processor: %w", err)
+	}
+
+	fn, ok := goja.AssertFunction(vm.Get(EntryFunction))
+	if !ok {
+		return nil, fmt.Errorf("processor does not define a '%s(event)' function", EntryFunction)
+	}
+	return &Runner{vm: vm, fn: fn, timeout: timeout}, nil
+}
+
+// Run applies the processor to one JSON event and returns the resulting events,
+// which is empty when the event was dropped.
+func (r *Runner) Run(event json.RawMessage) (output []json.RawMessage, err error) {
+	var doc interface{}
+	if err := json.Unmarshal(event, &doc); err != nil {
+		return nil, fmt.Errorf("invalid JSON event: %w", err)
+	}
+
+	// Stop runaway scripts (e.g. infinite loops) instead of hanging the test
+	if r.timeout > 0 {
+		fired := make(chan struct{})
+		timer := time.AfterFunc(r.timeout, func() {
+			r.vm.Interrupt(fmt.Sprintf("processor timed out after %s", r.timeout))
+			close(fired)
+		})
+		// Stop the timer (or wait for it to finish firing) before clearing,
+		// or the interrupt could land after and fail the next event
+		defer func() {
+			if !timer.Stop() {
+				<-fired
+			}
+			r.vm.ClearInterrupt()
+		}()
+	}
+
+	value, err := r.fn(goja.Undefined(), r.vm.ToValue(doc))
+	if err != nil {
+		return nil, err
+	}
+
+	if value == nil || goja.IsUndefined(value) || goja.IsNull(value) {
+		return nil, nil
+	}
+	result := value.Export()
+	if b, ok := result.(bool); ok && !b {
+		return nil, nil
+	}
+
+	events, ok := result.([]interface{})
+	if !ok {
+		if _, ok := result.(map[string]interface{}); !ok {
+			return nil, fmt.Errorf("'%s' returned %s, expected an object, an array of objects, or null, undefined or false to drop the event", EntryFunction, valueType(result))
+		}
+		events = []interface{}{result}
+	}
+	for i, e := range events {
+		if _, ok := e.(map[string]interface{}); !ok {
+			return nil, fmt.Errorf("'%s' returned an array with %s at index %d, expected only objects", EntryFunction, valueType(e), i)
+		}
+		b, err := json.Marshal(e)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode processor output: %w", err)
+		}
+		output = append(output, b)
+	}
+	return output, nil
+}
+
+// valueType names the JavaScript type of an exported value, with its article, for error messages
+func valueType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "a string"
+	case bool:
+		return "a boolean"
+	case int64, float64:
+		return "a number"
+	case []interface{}:
+		return "an array"
+	case map[string]interface{}:
+		return "an object"
+	}
+	return fmt.Sprintf("a %T", v)
+}
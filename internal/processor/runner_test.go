@@ -0,0 +1,60 @@
+package processor
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunnerRun(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string // body of process(event)
+		want    []string
+		wantErr string
+	}{
+		{name: "object", body: "event.seen = true; return event;", want: []string{`{"n":1,"seen":true}`}},
+		{name: "array", body: "return [event, {n: 2}];", want: []string{`{"n":1}`, `{"n":2}`}},
+		{name: "empty array", body: "return [];"},
+		{name: "null drops", body: "return null;"},
+		{name: "undefined drops", body: "return;"},
+		{name: "false drops", body: "return false;"},
+		{name: "string", body: "return 'x';", wantErr: "'process' returned a string, expected an object"},
+		{name: "number", body: "return 42;", wantErr: "'process' returned a number"},
+		{name: "true", body: "return true;", wantErr: "'process' returned a boolean"},
+		{name: "array of strings", body: "return [event, 'x'];", wantErr: "array with a string at index 1"},
+		{name: "array with null", body: "return [null];", wantErr: "array with null at index 0"},
+		{name: "nested array", body: "return [[event]];", wantErr: "array with an array at index 0"},
+		{name: "exception", body: "throw new Error('boom');", wantErr: "boom"},
+		{name: "timeout", body: "for (;;) {}", wantErr: "processor timed out after 50ms"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			script := "function process(event) {\n" + tt.body + "\n}\n"
+			r, err := NewRunner("p.js", []byte(script), io.Discard, 50*time.Millisecond)
+			if err != nil {
+				t.Fatalf("NewRunner() error = %v", err)
+			}
+			got, err := r.Run(json.RawMessage(`{"n":1}`))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Run() = %s, %v, want error %q", got, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			var gotStrings []string
+			for _, e := range got {
+				gotStrings = append(gotStrings, string(e))
+			}
+			if !reflect.DeepEqual(gotStrings, tt.want) {
+				t.Errorf("Run() = %q, want %q", gotStrings, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package secrets
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Resolver fetches a secret value from a backend. ref is the part of the
+// value after "<scheme>:", e.g. "MY_TOKEN" for "env:MY_TOKEN".
+type Resolver interface {
+	Resolve(ref string) (string, error)
+}
+
+// ResolverFunc adapts a function to the Resolver interface
+type ResolverFunc func(ref string) (string, error)
+
+func (f ResolverFunc) Resolve(ref string) (string, error) {
+	return f(ref)
+}
+
+var (
+	mu        sync.RWMutex
+	resolvers = map[string]Resolver{}
+)
+
+// Register makes a backend available under "<scheme>:". Registering a scheme twice replaces it.
+func Register(scheme string, r Resolver) {
+	mu.Lock()
+	defer mu.Unlock()
+	resolvers[scheme] = r
+}
+
+// Schemes returns the registered scheme names, sorted
+func Schemes() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(resolvers))
+	for name := range resolvers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Resolve turns a flag value into the secret it refers to:
+//   - "@path" reads a file (kept for compatibility with "file:path")
+//   - "literal:value" is value as is, to escape values looking like a reference
+//   - "<scheme>:ref" is handed to the registered resolver
+//   - anything else, including values with an unregistered prefix such as "https:", is literal
+func Resolve(value string) (string, error) {
+	if len(value) > 1 && value[0] == '@' {
+		return readFile(value[1:])
+	}
+
+	scheme, ref, found := strings.Cut(value, ":")
+	if !found {
+		return value, nil
+	}
+	mu.RLock()
+	r, ok := resolvers[scheme]
+	mu.RUnlock()
+	if !ok {
+		return value, nil
+	}
+	secret, err := r.Resolve(ref)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", scheme, err)
+	}
+	return secret, nil
+}
+
+// IsReference reports whether Resolve reads value from a secret source rather than
+// returning it as is. "literal:" values are not references.
+func IsReference(value string) bool {
+	if len(value) > 1 && value[0] == '@' {
+		return true
+	}
+	scheme, _, found := strings.Cut(value, ":")
+	if !found || scheme == "literal" {
+		return false
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	_, ok := resolvers[scheme]
+	return ok
+}
+
+func init() {
+	Register("literal", ResolverFunc(func(ref string) (string, error) { return ref, nil }))
+	Register("file", ResolverFunc(readFile))
+	Register("env", ResolverFunc(readEnv))
+	Register("stdin", &stdinResolver{in: os.Stdin})
+	Register("exec", ResolverFunc(readExec))
+}
+
+func readFile(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+	return string(content), nil
+}
+
+func readEnv(name string) (string, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable '%s' is not set", name)
+	}
+	return value, nil
+}
+
+// readExec runs the command with the shell and returns its output without
+// the trailing newline, e.g. "exec:pass show ingext/slack".
+func readExec(command string) (string, error) {
+	if strings.TrimSpace(command) == "" {
+		return "", fmt.Errorf("no command given")
+	}
+	var stdout bytes.Buffer
+	c := exec.Command("sh", "-c", command)
+	c.Stdout = &stdout
+	c.Stderr = os.Stderr
+	if err := c.Run(); err != nil {
+		return "", fmt.Errorf("command '%s' failed: %w", command, err)
+	}
+	return strings.TrimRight(stdout.String(), "\r\n"), nil
+}
+
+// stdinResolver reads stdin once, so "stdin:" can be used by several keys
+type stdinResolver struct {
+	in    io.Reader
+	once  sync.Once
+	value string
+	err   error
+}
+
+func (s *stdinResolver) Resolve(ref string) (string, error) {
+	s.once.Do(func() {
+		b, err := io.ReadAll(s.in)
+		if err != nil {
+			s.err = fmt.Errorf("failed to read from stdin: %w", err)
+			return
+		}
+		s.value = strings.TrimRight(string(b), "\r\n")
+	})
+	return s.value, s.err
+}
@@ -0,0 +1,102 @@
+package secrets
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "token")
+	if err := os.WriteFile(path, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("INGEXT_TEST_SECRET", "from-env")
+	Register("test", ResolverFunc(func(ref string) (string, error) { return "resolved " + ref, nil }))
+	t.Cleanup(func() {
+		mu.Lock()
+		delete(resolvers, "test")
+		mu.Unlock()
+	})
+
+	tests := []struct {
+		value   string
+		want    string
+		wantErr string
+	}{
+		{value: "s3cr3t", want: "s3cr3t"},
+		{value: "", want: ""},
+		{value: "@", want: "@"},
+		{value: "https://hooks.example.com/x", want: "https://hooks.example.com/x"},
+		{value: "user:pass", want: "user:pass"},
+		{value: "literal:env:INGEXT_TEST_SECRET", want: "env:INGEXT_TEST_SECRET"},
+		{value: "literal:@" + path, want: "@" + path},
+		{value: "literal:", want: ""},
+		{value: "@" + path, want: "from-file\n"},
+		{value: "file:" + path, want: "from-file\n"},
+		{value: "@" + filepath.Join(dir, "missing"), wantErr: "failed to read file"},
+		{value: "env:INGEXT_TEST_SECRET", want: "from-env"},
+		{value: "env:INGEXT_TEST_UNSET", wantErr: "env: environment variable 'INGEXT_TEST_UNSET' is not set"},
+		{value: "exec:echo hello", want: "hello"},
+		{value: "exec:exit 3", wantErr: "exec: command 'exit 3' failed"},
+		{value: "exec: ", wantErr: "exec: no command given"},
+		{value: "test:ref", want: "resolved ref"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := Resolve(tt.value)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Resolve() = %q, %v, want error %q", got, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Resolve() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsReference(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{value: "s3cr3t", want: false},
+		{value: "@", want: false},
+		{value: "@/etc/passwd", want: true},
+		{value: "file:/etc/passwd", want: true},
+		{value: "env:HOME", want: true},
+		{value: "stdin:", want: true},
+		{value: "exec:id", want: true},
+		{value: "https://x", want: false},
+		{value: "literal:exec:id", want: false},
+		{value: "exec", want: false},
+	}
+	for _, tt := range tests {
+		if got := IsReference(tt.value); got != tt.want {
+			t.Errorf("IsReference(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestStdinResolverReadsOnce(t *testing.T) {
+	s := &stdinResolver{in: strings.NewReader("s3cr3t\n")}
+	for i := 0; i < 2; i++ {
+		got, err := s.Resolve("")
+		if err != nil {
+			t.Fatalf("Resolve() error = %v", err)
+		}
+		// Every key using stdin: gets the value read the first time
+		if got != "s3cr3t" {
+			t.Errorf("Resolve() call %d = %q, want %q", i+1, got, "s3cr3t")
+		}
+	}
+}